@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/4sp1/yac/internal/agent"
+	"github.com/4sp1/yac/internal/provider"
+
+	"go.uber.org/zap"
+)
+
+// candidateTemperature spreads the k candidates temperatures from 0.4 to 1 so
+// that they differ from one another.
+func candidateTemperature(i, k int) float64 {
+	if k <= 1 {
+		return 0
+	}
+	return 0.4 + 0.6*float64(i)/float64(k-1)
+}
+
+// postCandidates asks the provider for k commit messages concurrently, each
+// sampled at its own temperature. The failed requests are dropped unless they
+// all failed.
+func (cc *commitClient) postCandidates(ctx context.Context, agent agent.Agent, k int) error {
+	if err := cc.preparePrompt(agent); err != nil {
+		return err
+	}
+	var (
+		wg   sync.WaitGroup
+		res  = make([]provider.Response, k)
+		errs = make([]error, k)
+	)
+	start := time.Now()
+	for i := range k {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := cc.request()
+			req.Temperature = candidateTemperature(i, k)
+			res[i], errs[i] = cc.provider.Complete(ctx, req)
+		}()
+	}
+	wg.Wait()
+	cc.latency = time.Since(start)
+	cc.candidates = cc.candidates[:0]
+	cc.candidateTemps = cc.candidateTemps[:0]
+	for i := range k {
+		if errs[i] != nil {
+			cc.logger.Warn("candidate failed", zap.Int("candidate", i), zap.Error(errs[i]))
+			continue
+		}
+		cc.candidates = append(cc.candidates, res[i])
+		cc.candidateTemps = append(cc.candidateTemps, candidateTemperature(i, k))
+	}
+	if len(cc.candidates) == 0 {
+		return fmt.Errorf("%s: %w", cc.provider, errors.Join(errs...))
+	}
+	return nil
+}
+
+// choose keeps the i-th candidate as the commit message.
+func (cc *commitClient) choose(i int) {
+	cc.response = cc.candidates[i]
+	cc.temperature = cc.candidateTemps[i]
+	cc.commitBody = cc.response.Text
+}
+
+var errRegenerate = errors.New("regenerate candidates")
+
+// pickCandidate shows the candidates on out and reads the number of the chosen
+// one on in, "r" asks for new candidates instead.
+func pickCandidate(in *bufio.Reader, out io.Writer, candidates []provider.Response) (int, error) {
+	for i, c := range candidates {
+		fmt.Fprintf(out, "\n%s[%d]%s\n%s\n", printGreen, i+1, printReset, strings.TrimSpace(c.Text))
+	}
+	for {
+		fmt.Fprintf(out, "\npick a commit message [1-%d], r to regenerate: ", len(candidates))
+		line, err := in.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "r" {
+			return 0, errRegenerate
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(candidates) {
+			return n - 1, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read choice: %w", err)
+		}
+	}
+}
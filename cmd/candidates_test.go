@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/4sp1/yac/internal/agent"
+	"github.com/4sp1/yac/internal/provider"
+
+	"go.uber.org/zap"
+)
+
+// sampler answers with the request temperature, failing on the coldest one.
+type sampler struct{}
+
+func (sampler) String() string { return "sampler" }
+
+func (sampler) Complete(ctx context.Context, r provider.Request) (provider.Response, error) {
+	if r.Temperature == candidateTemperature(0, 3) {
+		return provider.Response{}, errors.New("cold")
+	}
+	return provider.Response{Text: fmt.Sprintf("feat: sampled at %.1f", r.Temperature)}, nil
+}
+
+func TestPostCandidates(t *testing.T) {
+	a, err := agent.New()
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	cc := commitClient{provider: sampler{}, logger: zap.NewNop()}
+	if err := cc.postCandidates(context.Background(), a, 3); err != nil {
+		t.Fatalf("post candidates: %s", err)
+	}
+	if len(cc.candidates) != 2 || cc.candidates[1].Text != "feat: sampled at 1.0" {
+		t.Fatalf("unexpected candidates %+v", cc.candidates)
+	}
+	cc.choose(0)
+	if cc.commitBody != "feat: sampled at 0.7" {
+		t.Fatalf("unexpected commit body %q", cc.commitBody)
+	}
+}
+
+func TestPickCandidate(t *testing.T) {
+	candidates := []provider.Response{{Text: "feat: one"}, {Text: "fix: two"}}
+	for _, test := range []struct {
+		input         string
+		expected      int
+		expectedError error
+	}{
+		{input: "2\n", expected: 1},
+		{input: "3\nnope\n1\n", expected: 0},
+		{input: "r\n", expectedError: errRegenerate},
+		{input: "", expectedError: io.EOF},
+	} {
+		got, err := pickCandidate(bufio.NewReader(strings.NewReader(test.input)), io.Discard, candidates)
+		if !errors.Is(err, test.expectedError) {
+			t.Fatalf("%q: expected error %v got %v", test.input, test.expectedError, err)
+		}
+		if got != test.expected {
+			t.Fatalf("%q: expected %d got %d", test.input, test.expected, got)
+		}
+	}
+}
@@ -1,150 +1,301 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/4sp1/yac/internal/agent"
 	"github.com/4sp1/yac/internal/commit/config"
+	"github.com/4sp1/yac/internal/commit/message"
 	"github.com/4sp1/yac/internal/commit/scope"
 	"github.com/4sp1/yac/internal/commit/wip"
+	"github.com/4sp1/yac/internal/history"
+	"github.com/4sp1/yac/internal/provider"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 )
 
-type vertexClient struct {
-	projectId string
-	model     string
-	location  string
-	token     string
-
-	commitBody string
-	userPrompt string
+type commitClient struct {
+	provider provider.Provider
+	// stream, when set, renders the commit message while it is generated
+	stream io.Writer
+	// streamed is the last answer rendered on stream
+	streamed string
+
+	commitBody   string
+	systemPrompt string
+	userPrompt   string
+	response     provider.Response
+	// temperature and latency of the request the response answers
+	temperature float64
+	latency     time.Duration
+	// candidates are the alternative answers the response is picked from,
+	// sampled at candidateTemps
+	candidates     []provider.Response
+	candidateTemps []float64
+	// turns follow the user prompt when the answer is refined
+	turns []provider.Message
 
 	logger *zap.Logger
 }
 
-func (vc *vertexClient) updateToken() error {
-	{
-		out, err := exec.Command("gcloud", "auth", "print-access-token").Output()
-		if err != nil {
-			return err
-		}
-		vc.token = string(out[:len(out)-1])
-		vc.logger.Debug("googlcloud aiplatform token retrieved")
+func (cc *commitClient) preparePrompt(agent agent.Agent) error {
+	system, err := agent.SystemPrompt()
+	if err != nil {
+		return fmt.Errorf("agent: system prompt: %w", err)
 	}
-	return nil
-}
-
-func (vc *vertexClient) preparePrompt(agent agent.Agent) error {
+	cc.systemPrompt = system
 	user, err := agent.UserPrompt()
 	if err != nil {
 		return fmt.Errorf("agent: user prompt: %w", err)
 	}
-	vc.userPrompt = user
+	cc.userPrompt = user
 	return nil
 }
 
-func (vc *vertexClient) post(agent agent.Agent) error {
-	var url = fmt.Sprintf(
-		"https://aiplatform.googleapis.com/v1/projects/%[3]s/locations/%[2]s/publishers/anthropic/models/%[1]s:streamRawPredict",
-		vc.model, vc.location, vc.projectId)
-
-	var err error
-	defer func() {
-		if err != nil {
-			vc.logger.Error("vertexClient operator", zap.Error(err))
-		}
-	}()
-
-	vc.logger.Debug("vertex ai post", zap.String("url", url))
+// request is the provider request for the prepared prompt and the refine
+// turns that followed.
+func (cc *commitClient) request() provider.Request {
+	req := provider.NewRequest(cc.userPrompt)
+	req.System = cc.systemPrompt
+	req.Messages = append(req.Messages, cc.turns...)
+	return req
+}
 
-	if err := vc.preparePrompt(agent); err != nil {
+func (cc *commitClient) post(ctx context.Context, agent agent.Agent) error {
+	if err := cc.preparePrompt(agent); err != nil {
 		return err
 	}
+	return cc.complete(ctx)
+}
 
-	payload := struct {
-		Version   string      `json:"anthropic_version"`
-		Messges   []claudeMsg `json:"messages"`
-		System    string      `json:"system"`
-		Stream    bool        `json:"stream"`
-		MaxTokens int         `json:"max_tokens"`
-	}{
-		Version: "vertex-2023-10-16",
-		Messges: []claudeMsg{
-			{
-				Role:    "user",
-				Content: []claudeMsgContent{newClaudeMsgTxt(vc.userPrompt)},
-			},
-		},
-		MaxTokens: 3072,
-		Stream:    false,
+// complete sends the request and keeps the answer as the commit message.
+func (cc *commitClient) complete(ctx context.Context) error {
+	cc.logger.Debug("provider complete", zap.Stringer("provider", cc.provider))
+	req := cc.request()
+	req.Stream = cc.stream
+	start := time.Now()
+	res, err := cc.provider.Complete(ctx, req)
+	cc.latency = time.Since(start)
+	if cc.stream != nil {
+		fmt.Fprintln(cc.stream)
+	}
+	cc.logger.Debug("provider response",
+		zap.String("provider", res.Provider),
+		zap.String("model", res.Model),
+		zap.String("stop_reason", res.StopReason),
+		zap.Int("input_tokens", res.Usage.InputTokens),
+		zap.Int("output_tokens", res.Usage.OutputTokens))
+	if err != nil {
+		return fmt.Errorf("%s: %w", cc.provider, err)
+	}
+	cc.response = res
+	cc.temperature = req.Temperature
+	cc.commitBody = res.Text
+	if cc.stream != nil {
+		cc.streamed = res.Text
 	}
+	return nil
+}
 
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(payload); err != nil {
-		return err
+// revised tells whether the commit message differs from the streamed answer,
+// after the repair loop, the scope enforcement or the formatting.
+func (cc *commitClient) revised() bool {
+	return cc.stream != nil && cc.commitBody != cc.streamed
+}
+
+// record archives the request answered by the kept response, and the commit
+// message written out of it.
+func (cc *commitClient) record(tag string) history.Record {
+	req := cc.request()
+	req.Temperature = cc.temperature
+	r := history.NewRecord(tag, req, cc.response, cc.latency)
+	if cc.commitBody != cc.response.Text {
+		r.Message = cc.commitBody
 	}
+	return r
+}
 
-	req, err := http.NewRequest(http.MethodPost, url, &buf)
+// summarize answers a map-reduce chunk prompt, without streaming.
+func (cc *commitClient) summarize(ctx context.Context, prompt string) (string, error) {
+	res, err := cc.provider.Complete(ctx, provider.NewRequest(prompt))
 	if err != nil {
-		return err
+		return "", fmt.Errorf("%s: %w", cc.provider, err)
 	}
+	cc.logger.Debug("chunk summary",
+		zap.String("provider", res.Provider),
+		zap.Int("input_tokens", res.Usage.InputTokens),
+		zap.Int("output_tokens", res.Usage.OutputTokens))
+	return res.Text, nil
+}
 
-	if err = vc.updateToken(); err != nil {
-		return fmt.Errorf("updateToken: %w", err)
+// newProvider builds the resolved providers: the fallback providers, from
+// --fallback or the config, are tried in order when the primary one fails.
+// The providers that cannot be built, a missing api key for instance, are
+// left out of the chain unless none of them can.
+func newProvider(cmd *cobra.Command, flags providerFlags, c config.Flags,
+	logger *zap.Logger) (provider.Provider, error) {
+	var (
+		providers []provider.Provider
+		errs      []error
+	)
+	for _, p := range resolveProviders(cmd, flags, c) {
+		pr, err := buildProvider(p, flags, logger)
+		if err != nil {
+			err = fmt.Errorf("provider %q: %w", p.Name, err)
+			logger.Warn("provider skipped", zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		providers = append(providers, pr)
+	}
+	if len(providers) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	p, err := provider.NewChain(logger, providers...)
+	if err != nil {
+		return nil, err
 	}
+	if *flags.record {
+		p = provider.NewRecorder(p, *flags.fixtures)
+	}
+	return p, nil
+}
 
-	vc.logger.Debug("new request for vertexai api",
-		zap.String("anthropic_version", payload.Version),
-		zap.Int("max_tokens", payload.MaxTokens),
-		zap.String("model", vc.model),
-		zap.String("location", vc.location),
-		zap.String("project_id", vc.projectId))
+// resolveProviders returns the primary provider from command flags, falling
+// back to the prepared config for the values that were not set on the command
+// line, followed by the fallback providers.
+func resolveProviders(cmd *cobra.Command, flags providerFlags,
+	c config.Flags) []config.Provider {
+	primary := config.Provider{
+		Name:    *flags.name,
+		Model:   *flags.model,
+		BaseURL: *flags.baseURL,
+	}
+	var fallback []config.Provider
+	for _, f := range *flags.fallback {
+		name, model, _ := strings.Cut(f, ":")
+		fallback = append(fallback, config.Provider{Name: name, Model: model})
+	}
+	if c != nil {
+		p := c.FlagsProvider()
+		if !cmd.Flags().Changed("provider") && p.Name != "" {
+			primary.Name = p.Name
+		}
+		if !cmd.Flags().Changed("model") && p.Model != "" {
+			primary.Model = p.Model
+		}
+		if !cmd.Flags().Changed("base-url") && p.BaseURL != "" {
+			primary.BaseURL = p.BaseURL
+		}
+		if !cmd.Flags().Changed("fallback") {
+			fallback = c.FlagsFallback()
+		}
+	}
+	return append([]config.Provider{primary}, fallback...)
+}
 
-	req.Header.Add("Authorization", "Bearer "+vc.token)
-	req.Header.Add("Content-Type", "application/json; charset=utf-8")
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+// tokenBudget is the prompt budget fitting the smallest context window of
+// the providers, once the answer is accounted for. It is 0, for no budget,
+// when none of the windows are known.
+func tokenBudget(providers []config.Provider) int {
+	var window int
+	for _, p := range providers {
+		kind, err := provider.ParseKind(p.Name)
+		if err != nil {
+			continue
+		}
+		if w := provider.ContextWindow(kind, p.Model); w > 0 && (window == 0 || w < window) {
+			window = w
+		}
 	}
-	defer func() {
-		err = res.Body.Close()
-	}()
-	vc.logger.Debug("Vertex AI API response returned",
-		zap.String("status", res.Status))
+	if window == 0 {
+		return 0
+	}
+	return max(window-provider.DefaultMaxTokens, 0)
+}
 
-	var out bytes.Buffer
-	_, err = io.Copy(&out, res.Body)
+func buildProvider(p config.Provider, flags providerFlags,
+	logger *zap.Logger) (provider.Provider, error) {
+	kind, err := provider.ParseKind(p.Name)
 	if err != nil {
-		return fmt.Errorf("io copy body: %w", err)
-	}
-	valid := strings.ToValidUTF8(out.String(), "?")
-	r := strings.NewReader(valid)
-	vc.logger.Debug("to valid utf-8", zap.String("valid?", valid))
-	{
-		var buf bytes.Buffer
-		xc := exec.Command("jq", "-r", ".content[0].text")
-		xc.Stdin = r
-		xc.Stdout = &buf
-		xc.Stderr = &buf
-		if err = xc.Run(); err != nil {
-			return fmt.Errorf("jq extract: %w", err)
-		}
-		vc.commitBody = buf.String()
+		return nil, err
+	}
+	baseURL := p.BaseURL
+	if kind == provider.Ollama && baseURL == "" {
+		baseURL = os.Getenv("OLLAMA_HOST")
+	}
+	opts := []provider.Option{
+		provider.WithLogger(logger),
+		provider.WithModel(p.Model),
+		provider.WithBaseURL(baseURL),
+		provider.WithRetry(provider.RetryPolicy{
+			MaxAttempts: *flags.attempts,
+			BaseDelay:   provider.DefaultRetryPolicy.BaseDelay,
+			MaxDelay:    provider.DefaultRetryPolicy.MaxDelay,
+			Timeout:     *flags.timeout,
+		}),
+	}
+	switch kind {
+	case provider.Vertex:
+		opts = append(opts,
+			provider.WithProject(os.Getenv("GCP_VERTEXAI_PROJECT")),
+			provider.WithCredentials(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")))
+	case provider.Anthropic:
+		opts = append(opts, provider.WithAPIKey(os.Getenv("ANTHROPIC_API_KEY")))
+	case provider.Openai:
+		opts = append(opts, provider.WithAPIKey(os.Getenv("OPENAI_API_KEY")))
+	case provider.Replay:
+		opts = append(opts, provider.WithFixtures(*flags.fixtures))
 	}
+	return provider.New(kind, opts...)
+}
 
-	return nil
+type providerFlags struct {
+	name     *string
+	model    *string
+	baseURL  *string
+	fallback *[]string
+	attempts *int
+	timeout  *time.Duration
+	record   *bool
+	fixtures *string
+}
 
+func newProviderFlags(cmd *cobra.Command, kind provider.Kind) providerFlags {
+	kinds := make([]string, provider.UpperBound)
+	for k := range provider.UpperBound {
+		kinds[k] = k.Flag()
+	}
+	return providerFlags{
+		name: cmd.Flags().String("provider", kind.Flag(),
+			fmt.Sprintf("llm provider (%s)", strings.Join(kinds, ", "))),
+		model: cmd.Flags().String("model", "",
+			"provider model (empty for provider default)"),
+		baseURL: cmd.Flags().String("base-url", "",
+			"provider endpoint base url (empty for provider default)"),
+		fallback: cmd.Flags().StringArray("fallback", []string{},
+			"provider[:model] tried when the previous ones fail (can be repeated)"),
+		attempts: cmd.Flags().Int("attempts", provider.DefaultRetryPolicy.MaxAttempts,
+			"provider call attempts on transient errors (1 disables retries)"),
+		timeout: cmd.Flags().Duration("timeout", provider.DefaultRetryPolicy.Timeout,
+			"provider call timeout per attempt (0 for none)"),
+		record: cmd.Flags().Bool("record", false,
+			"record provider responses to --fixtures for the replay provider"),
+		fixtures: cmd.Flags().String("fixtures", provider.DefaultFixtures,
+			"recorded responses directory"),
+	}
 }
 
 func newScopt() []*bool {
@@ -268,7 +419,14 @@ func newCommandClaudeCommitFlags() *cobra.Command {
 }
 
 func newCommandClaudeCommit() *cobra.Command {
+	return newCommandCommit(provider.Vertex)
+}
+
+// newCommandCommit builds the commit command answered by the kind provider
+// unless --provider says otherwise.
+func newCommandCommit(kind provider.Kind) *cobra.Command {
 	var jj, noCommitOpt, noPost, debugDev, debugPrompt *bool
+	var templateName, profile *string
 	var isJsonConfig *bool
 
 	var wipt = make(map[wip.Context]*[]string)
@@ -278,11 +436,24 @@ func newCommandClaudeCommit() *cobra.Command {
 	var configPath *string
 	var prepare, noPrepare *bool
 
-	var stdout *bool
+	var stdout, stream *bool
+	var budget *int
+	var mapReduce *string
+	var jobs *int
+	var examples *int
+	var examplesScope, examplesPaths *bool
+	var candidates *int
+	var refine *bool
+	var repairs *int
+	var format *bool
+	var archive *bool
+	var archiveDir *string
+
+	var providerOpts providerFlags
 
 	cmd := &cobra.Command{
 		Use:          "commit",
-		Short:        "ask claude for a good commit message (vertexai)",
+		Short:        fmt.Sprintf("ask %s for a good commit message", kind.Flag()),
 		SilenceUsage: true,
 		Args: func(cmd *cobra.Command, args []string) error {
 			var set bool
@@ -294,6 +465,23 @@ func newCommandClaudeCommit() *cobra.Command {
 					set = true
 				}
 			}
+			if *profile != "" && cmd.Flags().Changed("template") {
+				return fmt.Errorf("--profile and --template are exclusive")
+			}
+			if *mapReduce != "" {
+				if _, err := agent.ParseSplit(*mapReduce); err != nil {
+					return err
+				}
+			}
+			if *candidates < 1 {
+				return fmt.Errorf("--candidates must be at least 1")
+			}
+			if *candidates > 1 && *stream {
+				return fmt.Errorf("--candidates and --stream are exclusive")
+			}
+			if *repairs < 0 {
+				return fmt.Errorf("--repair must not be negative")
+			}
 			_, err := checkConfigFlags(*isJsonConfig)
 			return err
 		},
@@ -339,11 +527,8 @@ func newCommandClaudeCommit() *cobra.Command {
 
 			debug.Debug("--no-commit flag valued", zap.Bool("no-commit", *noCommitOpt))
 
-			vc := vertexClient{
-				projectId: os.Getenv("GCP_VERTEXAI_PROJECT"),
-				model:     "claude-sonnet-4-5@20250929",
-				location:  "global",
-				logger:    debug,
+			cc := commitClient{
+				logger: debug,
 			}
 
 			var hasConfig bool
@@ -401,6 +586,16 @@ func newCommandClaudeCommit() *cobra.Command {
 					opts = append(opts, agent.WithGitLog(hash))
 				}
 			}
+			ex := agent.Examples{
+				Count:     *examples,
+				SameScope: *examplesScope,
+				SamePaths: *examplesPaths,
+			}
+			if *jj {
+				opts = append(opts, agent.WithJujutsuExamples(ex))
+			} else {
+				opts = append(opts, agent.WithGitExamples(ex))
+			}
 			for i := range wip.UpperBound {
 				for _, note := range *wipt[i] {
 					opts = append(opts, agent.WithNote(note, i))
@@ -414,6 +609,17 @@ func newCommandClaudeCommit() *cobra.Command {
 			}
 			opts = append(opts, agent.WithLogger(debug))
 
+			if *profile != "" {
+				opts = append(opts, agent.WithProfile(*profile))
+			} else {
+				root, _, err := vcsRoot()
+				if err != nil {
+					return fmt.Errorf("vcs root: %w", err)
+				}
+				opts = append(opts, agent.WithTemplate(*templateName,
+					agent.TemplateDirs(root)...))
+			}
+
 			// parse flags from config
 
 			if v != nil {
@@ -425,29 +631,143 @@ func newCommandClaudeCommit() *cobra.Command {
 						opts = append(opts, agent.WithNote(note, section))
 					}
 				}
+				if finalScope == scope.Other && v.FlagsScope() != "" {
+					finalScope, err = scope.Parse(v.FlagsScope())
+					if err != nil {
+						return fmt.Errorf("config: %w", err)
+					}
+					opts = append(opts, agent.WithScope(finalScope))
+				}
 			}
 
 			debug.Debug("final scope setting", zap.String("scope", finalScope.String()))
 
+			if *budget == 0 {
+				*budget = tokenBudget(resolveProviders(cmd, providerOpts, v))
+			}
+			if *budget > 0 {
+				debug.Debug("token budget", zap.Int("tokens", *budget))
+				opts = append(opts, agent.WithTokenBudget(*budget))
+			}
+
+			cc.provider, err = newProvider(cmd, providerOpts, v, debug)
+			if err != nil {
+				return fmt.Errorf("new provider: %w", err)
+			}
+
+			debug.Debug("yag timestamp")
+			ts := tstampFormat{litt: false}
+			ts.update()
+
+			if *stream && !*noPost {
+				cc.stream = os.Stdout
+				if *stdout {
+					fmt.Println(ts.tag)
+				}
+			}
+
 			{
-				agent, err := agent.New(opts...)
+				a, err := agent.New(opts...)
 				if err != nil {
 					return fmt.Errorf("new agent: %w", err)
 				}
-				if !*noPost {
-					if err = vc.post(agent); err != nil {
-						return fmt.Errorf("vx client post: %w", err)
+				if *mapReduce != "" && !*noPost {
+					split, _ := agent.ParseSplit(*mapReduce)
+					a, err = a.MapReduce(cmd.Context(), split, *jobs, cc.summarize)
+					if err != nil {
+						return fmt.Errorf("map reduce: %w", err)
 					}
-				} else {
-					if err = vc.preparePrompt(agent); err != nil {
+				}
+				in := bufio.NewReader(cmd.InOrStdin())
+				switch {
+				case *noPost:
+					if err = cc.preparePrompt(a); err != nil {
 						return fmt.Errorf("prepare prompt: %w", err)
 					}
+				case *candidates > 1:
+					for {
+						if err = cc.postCandidates(cmd.Context(), a, *candidates); err != nil {
+							return fmt.Errorf("commit client post candidates: %w", err)
+						}
+						i, err := pickCandidate(in, cmd.ErrOrStderr(), cc.candidates)
+						if errors.Is(err, errRegenerate) {
+							continue
+						}
+						if err != nil {
+							return fmt.Errorf("pick candidate: %w", err)
+						}
+						cc.choose(i)
+						break
+					}
+				default:
+					if err = cc.post(cmd.Context(), a); err != nil {
+						return fmt.Errorf("commit client post: %w", err)
+					}
+				}
+				rules := scopeRules(message.DefaultRules, finalScope)
+				if breaking := a.BreakingChanges(); len(breaking) > 0 {
+					debug.Debug("possible breaking changes", zap.Int("count", len(breaking)))
+					rules.RequireBreaking = true
+				}
+				repair := func() error {
+					issues, err := cc.repair(cmd.Context(), rules, *repairs)
+					if err != nil {
+						return fmt.Errorf("repair: %w", err)
+					}
+					for _, issue := range issues {
+						fmt.Fprintln(cmd.ErrOrStderr(), red("lint: "+issue.String()))
+					}
+					return nil
+				}
+				if !*noPost {
+					if err = repair(); err != nil {
+						return err
+					}
+				}
+				if *refine && !*noPost {
+					previous := cc.commitBody
+					if err = cc.refineLoop(cmd.Context(), in, cmd.ErrOrStderr()); err != nil {
+						return fmt.Errorf("refine: %w", err)
+					}
+					// the refined message goes through the rules again
+					if cc.commitBody != previous {
+						if err = repair(); err != nil {
+							return err
+						}
+					}
 				}
 			}
 
-			debug.Debug("yag timestamp")
-			ts := tstampFormat{litt: false}
-			ts.update()
+			if !*noPost {
+				// the message is written anyway, the repair loop already
+				// asked for the scope
+				if body, err := enforceScope(cc.commitBody, finalScope); err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), red("scope: "+err.Error()))
+				} else if body != cc.commitBody {
+					cc.commitBody = body
+					for _, issue := range scopedHeaderIssues(body) {
+						fmt.Fprintln(cmd.ErrOrStderr(), red("lint: "+issue.String()))
+					}
+				}
+				if *format {
+					cc.commitBody = message.Format(cc.commitBody, message.DefaultRules.MaxBodyLine)
+				}
+			}
+
+			if !*noPost && *archive {
+				rec := cc.record(ts.tag)
+				rec.Config = rawConfig.String()
+				dir, err := historyDir(*archiveDir)
+				if err == nil {
+					var path string
+					if path, err = history.Save(dir, rec); err == nil {
+						debug.Debug("run archived", zap.String("path", path))
+					}
+				}
+				if err != nil {
+					debug.Warn("unable to archive the run", zap.Error(err))
+				}
+			}
 
 			// debugPrompt
 			if err := func(save bool) error {
@@ -482,8 +802,8 @@ func newCommandClaudeCommit() *cobra.Command {
 							debug.Warn("unable to close user prompt backup", zap.String("path", path), zap.Error(err))
 						}
 					}()
-					debug.Debug("user prompt", zap.String("prompt", vc.userPrompt))
-					_, err = io.Copy(f, strings.NewReader(vc.userPrompt))
+					debug.Debug("user prompt", zap.String("prompt", cc.userPrompt))
+					_, err = io.Copy(f, strings.NewReader(cc.userPrompt))
 					if err != nil {
 						return fmt.Errorf("io copy user prompt: %w", err)
 					}
@@ -493,6 +813,26 @@ func newCommandClaudeCommit() *cobra.Command {
 					}
 				}
 
+				// backup system prompt
+				if cc.systemPrompt != "" {
+					path := path.Join(".prompt", fmt.Sprintf("%s.system.md", ts.tag))
+					if err := os.WriteFile(path, []byte(cc.systemPrompt), 0600); err != nil {
+						return fmt.Errorf("write %q: %w", path, err)
+					}
+					fmt.Println("System prompt persists at", path)
+				}
+
+				// backup which provider answered the prompt
+				if !*noPost {
+					path := path.Join(".prompt", fmt.Sprintf("%s.provider", ts.tag))
+					b := fmt.Sprintf("provider: %s\nmodel: %s\n",
+						cc.response.Provider, cc.response.Model)
+					if err := os.WriteFile(path, []byte(b), 0600); err != nil {
+						return fmt.Errorf("write %q: %w", path, err)
+					}
+					fmt.Println("Answering provider persists at", path)
+				}
+
 				// backup user configured flags via jsonFlagsPath option
 				if hasConfig {
 					path := path.Join(".prompt", configMode.File(ts.tag))
@@ -524,7 +864,16 @@ func newCommandClaudeCommit() *cobra.Command {
 
 				var out io.Writer
 
+				revised := cc.stream == os.Stdout && cc.revised()
+				if revised {
+					fmt.Fprintln(os.Stderr, "\nthe streamed message was revised:")
+				}
+
 				out = os.Stdout
+				if cc.stream == os.Stdout && !revised {
+					// already rendered while streaming
+					out = io.Discard
+				}
 
 				if !*stdout {
 					debug.Debug("create commit-stash")
@@ -538,6 +887,9 @@ func newCommandClaudeCommit() *cobra.Command {
 						}
 					}()
 					out = f
+					if revised {
+						out = io.MultiWriter(f, os.Stdout)
+					}
 					debug.Debug(".commit-stash writer is ready")
 				}
 
@@ -546,7 +898,7 @@ func newCommandClaudeCommit() *cobra.Command {
 					return fmt.Errorf("write finalCommit: %w", err)
 				}
 
-				if _, err = fmt.Fprintln(w, vc.commitBody); err != nil {
+				if _, err = fmt.Fprintln(w, cc.commitBody); err != nil {
 					return fmt.Errorf("write commitMsgBody: %w", err)
 				}
 
@@ -594,14 +946,47 @@ func newCommandClaudeCommit() *cobra.Command {
 	isJsonConfig = cmd.Flags().Bool("json", false, "read config-json instead of config-yaml")
 
 	stdout = cmd.Flags().Bool("stdout", true, "write commit to stdout instead of .commit-stash")
+	stream = cmd.Flags().Bool("stream", false, "render the commit message while it is generated")
+	refine = cmd.Flags().Bool("refine", false,
+		"revise the commit message with follow-up instructions before writing it")
+	repairs = cmd.Flags().Int("repair", 2,
+		"send the lint issues of the commit message back to the provider this many times at most (0 to disable)")
+	format = cmd.Flags().Bool("format", true,
+		"rewrap the body to 72 columns and write the footers as git trailers")
+	candidates = cmd.Flags().Int("candidates", 1,
+		"generate this many alternative commit messages and pick one interactively")
 
 	debugDev = cmd.Flags().Bool("dev", false,
 		"enable zap dev logger")
 
 	debugPrompt = cmd.Flags().Bool("debug-prompt", false, "save prompts and config flags to .prompt")
+	archive = cmd.Flags().Bool("history", false,
+		"archive each run, diff and prompts included, for yac history")
+	archiveDir = cmd.Flags().String("history-dir", "",
+		"history directory (defaults to one per repository in the user cache dir)")
+
+	templateName = cmd.Flags().String("template", agent.DefaultTemplate,
+		"prompt template name looked up in .yac/templates, the user config dir, then the embedded ones")
+	profile = cmd.Flags().String("profile", "",
+		fmt.Sprintf("built-in prompt profile (%s) used instead of --template",
+			strings.Join(agent.Profiles(), ", ")))
 
 	noPost = cmd.Flags().Bool("no-post", false, "do not post to claude")
 
+	splits := make([]string, agent.SplitUpperBound)
+	for s := range agent.SplitUpperBound {
+		splits[s] = strings.ToLower(s.String())
+	}
+	mapReduce = cmd.Flags().String("map-reduce", "",
+		fmt.Sprintf("summarize the diff per %s before writing the message (empty to disable)",
+			strings.Join(splits, " or ")))
+	jobs = cmd.Flags().Int("jobs", 4, "concurrent summaries in --map-reduce mode")
+
+	budget = cmd.Flags().Int("token-budget", 0,
+		"prompt token budget shrinking large diffs (0 for the provider context window, -1 for none)")
+
+	providerOpts = newProviderFlags(cmd, kind)
+
 	noCommitOpt = cmd.Flags().Bool("no-commit", true,
 		"do not commit (review .commit_stash instead)")
 
@@ -613,6 +998,13 @@ func newCommandClaudeCommit() *cobra.Command {
 	logs = cmd.Flags().StringSlice("log",
 		[]string{}, "related commit hash (can be repeated)")
 
+	examples = cmd.Flags().Int("examples", 0,
+		"recent conventional commits shown as examples of the house style")
+	examplesScope = cmd.Flags().Bool("examples-scope", false,
+		"only show examples sharing the commit scope")
+	examplesPaths = cmd.Flags().Bool("examples-paths", false,
+		"only show examples touching the changed paths")
+
 	for i := range scope.UpperBound {
 		scopt[i] = cmd.Flags().Bool(i.Flag(), false, i.Label())
 	}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/4sp1/yac/internal/agent"
+	"github.com/4sp1/yac/internal/provider"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// newStagedRepo creates a git repository with a staged change and moves into
+// it for the duration of the test.
+func newStagedRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "yac")
+	t.Setenv("GIT_AUTHOR_EMAIL", "yac@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "yac")
+	t.Setenv("GIT_COMMITTER_EMAIL", "yac@example.com")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %s", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Errorf("chdir back: %s", err)
+		}
+	})
+	git(t, "init", "-q")
+	if err := os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0600); err != nil {
+		t.Fatalf("write main.go: %s", err)
+	}
+	git(t, "add", "main.go")
+	return dir
+}
+
+func git(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+// writeFixture records text as the answer to the prompt the commit command
+// is about to send.
+func writeFixture(t *testing.T, dir string, text string, opts ...agent.Option) {
+	t.Helper()
+	writeTurnFixture(t, dir, nil, text, opts...)
+}
+
+// writeTurnFixture records text as the answer to the prompt followed by the
+// given conversation turns.
+func writeTurnFixture(t *testing.T, dir string, turns []provider.Message, text string,
+	opts ...agent.Option) {
+	t.Helper()
+	a, err := agent.New(append([]agent.Option{agent.WithGitDiff()}, opts...)...)
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	cc := commitClient{turns: turns}
+	if err := cc.preparePrompt(a); err != nil {
+		t.Fatalf("prepare prompt: %s", err)
+	}
+	req := cc.request()
+	b, err := json.Marshal(map[string]any{
+		"messages": req.Messages,
+		"response": provider.Response{Text: text, Model: "golden"},
+	})
+	if err != nil {
+		t.Fatalf("marshal fixture: %s", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("mkdir fixtures: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, provider.FixtureKey(req)+".json"), b, 0600); err != nil {
+		t.Fatalf("write fixture: %s", err)
+	}
+}
+
+func TestCommitReplay(t *testing.T) {
+	repo := newStagedRepo(t)
+	fixtures := filepath.Join(repo, ".yac", "fixtures")
+	const golden = "feat(cli): Add empty main\n\nStart the command line entry point."
+	writeFixture(t, fixtures, golden)
+
+	cmd := newCommandClaudeCommit()
+	cmd.SetArgs([]string{
+		"--jj=false",
+		"--provider", "replay",
+		"--fixtures", fixtures,
+		"--stdout=false",
+		"--no-commit=false",
+	})
+	var stderr strings.Builder
+	cmd.SetErr(&stderr)
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("commit: %s", err)
+	}
+	// a golden message failing lint would go through a repair turn
+	if stderr.Len() > 0 {
+		t.Fatalf("unexpected output %q", stderr.String())
+	}
+
+	msg := git(t, "log", "-1", "--format=%B")
+	// the first line is the yac timestamp tag
+	_, body, _ := strings.Cut(msg, "\n")
+	if strings.TrimSpace(body) != golden {
+		t.Fatalf("expected\n%s\ngot\n%s", golden, body)
+	}
+}
+
+func TestNewProviderSkipsUnusable(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	cmd := &cobra.Command{}
+	flags := newProviderFlags(cmd, provider.Anthropic)
+	if err := cmd.Flags().Parse([]string{"--fallback", "replay"}); err != nil {
+		t.Fatalf("parse flags: %s", err)
+	}
+	p, err := newProvider(cmd, flags, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("new provider: %s", err)
+	}
+	if !strings.HasPrefix(p.String(), "replay") {
+		t.Fatalf("unexpected provider %s", p)
+	}
+
+	cmd = &cobra.Command{}
+	flags = newProviderFlags(cmd, provider.Anthropic)
+	if _, err := newProvider(cmd, flags, nil, zap.NewNop()); !errors.Is(err, provider.ErrMissingAPIKey) {
+		t.Fatalf("expected %v got %v", provider.ErrMissingAPIKey, err)
+	}
+}
+
+func TestOllamaCommitFlags(t *testing.T) {
+	cmd := newCommandOllamaCommit()
+	if err := cmd.ParseFlags([]string{"--dry"}); err != nil {
+		t.Fatalf("parse flags: %s", err)
+	}
+	if err := cmd.PreRunE(cmd, nil); err != nil {
+		t.Fatalf("pre run: %s", err)
+	}
+	for flag, expected := range map[string]string{"jj": "false", "no-post": "true"} {
+		if got := cmd.Flags().Lookup(flag).Value.String(); got != expected {
+			t.Fatalf("expected --%s=%s got %s", flag, expected, got)
+		}
+	}
+}
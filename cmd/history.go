@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/4sp1/yac/internal/history"
+	"github.com/4sp1/yac/internal/provider"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newCommandHistory() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "browse and replay the commit prompts archived with --history",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	dir := cmd.PersistentFlags().String("dir", "",
+		"history directory (defaults to the one of the repository in the user cache dir)")
+	cmd.AddCommand(
+		newCommandHistoryList(dir),
+		newCommandHistoryShow(dir),
+		newCommandHistoryReplay(dir))
+	return cmd
+}
+
+func newCommandHistoryList(dir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "list the archived runs, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := historyDir(*dir)
+			if err != nil {
+				return err
+			}
+			entries, err := history.List(d)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tTIME\tPROVIDER\tMODEL\tTOKENS\tLATENCY\tSUBJECT")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%s\t%s\n",
+					e.Name, e.Time.Format(time.DateTime), e.Response.Provider,
+					e.Response.Model, e.Response.Usage.InputTokens,
+					e.Response.Usage.OutputTokens, e.Latency.Round(time.Millisecond),
+					e.Subject())
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newCommandHistoryShow(dir *string) *cobra.Command {
+	var diff *string
+	cmd := &cobra.Command{
+		Use:   "show NAME",
+		Short: "show an archived run, or how its prompt differs from another one",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := historyDir(*dir)
+			if err != nil {
+				return err
+			}
+			r, err := history.Load(d, args[0])
+			if err != nil {
+				return err
+			}
+			if *diff != "" {
+				other, err := history.Load(d, *diff)
+				if err != nil {
+					return err
+				}
+				return diffPrompts(cmd, other, r)
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "tag: %s\ntime: %s\nprovider: %s\nmodel: %s\n",
+				r.Tag, r.Time.Format(time.DateTime), r.Response.Provider, r.Response.Model)
+			fmt.Fprintf(out, "stop reason: %s\ntokens: %d in, %d out\nlatency: %s\n",
+				r.Response.StopReason, r.Response.Usage.InputTokens,
+				r.Response.Usage.OutputTokens, r.Latency.Round(time.Millisecond))
+			if r.Temperature != 0 {
+				fmt.Fprintf(out, "temperature: %g\n", r.Temperature)
+			}
+			if r.Config != "" {
+				fmt.Fprintf(out, "\n## config\n\n%s\n", r.Config)
+			}
+			fmt.Fprintf(out, "\n%s## response\n\n%s\n", r.Prompt(), r.Response.Text)
+			if r.Message != "" {
+				fmt.Fprintf(out, "\n## commit message\n\n%s\n", r.Message)
+			}
+			return nil
+		},
+	}
+	diff = cmd.Flags().String("diff", "", "archived run whose prompt is compared with NAME")
+	return cmd
+}
+
+// diffPrompts shows how the prompt of b differs from the one of a, with git
+// diff.
+func diffPrompts(cmd *cobra.Command, a, b history.Record) error {
+	tmp, err := os.MkdirTemp("", "yac-history")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			fmt.Fprintln(cmd.ErrOrStderr(), "remove", tmp, err)
+		}
+	}()
+	paths := make([]string, 2)
+	for i, r := range []history.Record{a, b} {
+		paths[i] = filepath.Join(tmp, fmt.Sprintf("%d-%s.md", i, r.Tag))
+		if err := os.WriteFile(paths[i], []byte(r.Prompt()), 0600); err != nil {
+			return err
+		}
+	}
+	gd := exec.Command("git", "diff", "--no-index", paths[0], paths[1])
+	gd.Stdout = cmd.OutOrStdout()
+	gd.Stderr = cmd.ErrOrStderr()
+	var exitErr *exec.ExitError
+	// git diff exits with 1 when the files differ
+	if err := gd.Run(); err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return fmt.Errorf("git diff: %w", err)
+	}
+	return nil
+}
+
+func newCommandHistoryReplay(dir *string) *cobra.Command {
+	var providerOpts providerFlags
+	var stream *bool
+	cmd := &cobra.Command{
+		Use:          "replay NAME",
+		Short:        "send an archived prompt again, possibly to another provider or model",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := historyDir(*dir)
+			if err != nil {
+				return err
+			}
+			r, err := history.Load(d, args[0])
+			if err != nil {
+				return err
+			}
+			logger, err := zap.NewProduction()
+			if err != nil {
+				return fmt.Errorf("zap logger: %w", err)
+			}
+			p, err := newProvider(cmd, providerOpts, nil, logger.Named("history_replay"))
+			if err != nil {
+				return fmt.Errorf("new provider: %w", err)
+			}
+			req := r.Request()
+			if *stream {
+				req.Stream = cmd.OutOrStdout()
+			}
+			start := time.Now()
+			res, err := p.Complete(cmd.Context(), req)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p, err)
+			}
+			latency := time.Since(start)
+			if !*stream {
+				fmt.Fprintln(cmd.OutOrStdout(), res.Text)
+			} else {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+
+			ts := tstampFormat{}
+			if err := ts.update(); err != nil {
+				return fmt.Errorf("timestamp: %w", err)
+			}
+			replayed := history.NewRecord(ts.tag, r.Request(), res, latency)
+			replayed.Config = r.Config
+			path, err := history.Save(d, replayed)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s replayed by %s %s in %s, archived at %s\n",
+				args[0], res.Provider, res.Model, latency.Round(time.Millisecond), path)
+			return nil
+		},
+	}
+	providerOpts = newProviderFlags(cmd, provider.Vertex)
+	stream = cmd.Flags().Bool("stream", false, "render the answer while it is generated")
+	return cmd
+}
+
+// historyDir is dir or, when empty, the history directory of the current
+// repository.
+func historyDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	root, _, err := vcsRoot()
+	if err != nil {
+		return "", fmt.Errorf("vcs root: %w", err)
+	}
+	return history.Dir(root)
+}
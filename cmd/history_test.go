@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/4sp1/yac/internal/history"
+	"github.com/spf13/cobra"
+)
+
+func TestHistory(t *testing.T) {
+	repo := newStagedRepo(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	fixtures := filepath.Join(repo, ".yac", "fixtures")
+	const golden = "feat(cli): Add empty main"
+	writeFixture(t, fixtures, golden)
+
+	run := func(cmd *cobra.Command, args ...string) {
+		t.Helper()
+		cmd.SetArgs(args)
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("%q: %s", args, err)
+		}
+	}
+	run(newCommandClaudeCommit(), "--jj=false", "--provider", "replay",
+		"--fixtures", fixtures, "--stdout=false", "--no-commit=false", "--history")
+
+	dir, err := historyDir("")
+	if err != nil {
+		t.Fatalf("history dir: %s", err)
+	}
+	if strings.HasPrefix(dir, repo) {
+		t.Fatalf("history %q written in the working tree", dir)
+	}
+	entries, err := history.List(dir)
+	if err != nil {
+		t.Fatalf("list: %s", err)
+	}
+	if len(entries) != 1 || entries[0].Subject() != golden || entries[0].Response.Provider == "" {
+		t.Fatalf("unexpected archive %+v", entries)
+	}
+
+	var out bytes.Buffer
+	cmd := NewCLI()
+	cmd.SetOut(&out)
+	run(cmd, "history", "list")
+	if !strings.Contains(out.String(), entries[0].Name) || !strings.Contains(out.String(), golden) {
+		t.Fatalf("unexpected list\n%s", out.String())
+	}
+
+	out.Reset()
+	cmd = NewCLI()
+	cmd.SetOut(&out)
+	run(cmd, "history", "replay", entries[0].Name, "--provider", "replay", "--fixtures", fixtures)
+	if strings.TrimSpace(out.String()) != golden {
+		t.Fatalf("unexpected replay %q", out.String())
+	}
+	entries, err = history.List(dir)
+	if err != nil {
+		t.Fatalf("list: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the replay to be archived, got %d records", len(entries))
+	}
+
+	out.Reset()
+	cmd = NewCLI()
+	cmd.SetOut(&out)
+	run(cmd, "history", "show", entries[1].Name, "--diff", entries[0].Name)
+	if out.Len() != 0 {
+		t.Fatalf("expected identical prompts\n%s", out.String())
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/4sp1/yac/internal/commit/message"
+	"github.com/spf13/cobra"
+)
+
+func newCommandLint() *cobra.Command {
+	var (
+		rev            *string
+		types, scopes  *[]string
+		requireScope   *bool
+		maxHeader      *int
+		maxBodyLine    *int
+		capitalSubject *bool
+		breaking       *bool
+	)
+	cmd := &cobra.Command{
+		Use:   "lint [file|-]",
+		Short: "check a commit message against the conventional commits rules",
+		Long: `Check a commit message against the conventional commits rules.
+
+The message is read from .commit-stash by default, from the standard input
+with -, or from an existing commit with --rev. The timestamp tag line yac
+writes first is skipped.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				msg []byte
+				err error
+			)
+			switch {
+			case *rev != "":
+				if len(args) > 0 {
+					return fmt.Errorf("%w: both %s and --rev", errLintSource, args[0])
+				}
+				msg, err = exec.Command("git", "log", "-1", "--format=%B", *rev).Output()
+			case len(args) == 0:
+				msg, err = os.ReadFile(".commit-stash")
+			case args[0] == "-":
+				msg, err = io.ReadAll(cmd.InOrStdin())
+			default:
+				msg, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("read commit message: %w", err)
+			}
+			rules := message.Rules{
+				Types:           *types,
+				Scopes:          *scopes,
+				RequireScope:    *requireScope,
+				MaxHeader:       *maxHeader,
+				MaxBodyLine:     *maxBodyLine,
+				CapitalSubject:  *capitalSubject,
+				RequireBreaking: *breaking,
+			}
+			_, issues := rules.Lint(stripTag(string(msg)))
+			for _, issue := range issues {
+				fmt.Fprintln(cmd.OutOrStdout(), issue)
+			}
+			if len(issues) > 0 {
+				return fmt.Errorf("%w: %d issues", errLint, len(issues))
+			}
+			return nil
+		},
+	}
+	rules := message.DefaultRules
+	rev = cmd.Flags().String("rev", "", "lint the message of an existing commit")
+	types = cmd.Flags().StringSlice("types", rules.Types, "allowed types, empty for any")
+	scopes = cmd.Flags().StringSlice("scopes", rules.Scopes, "allowed scopes, empty for any")
+	requireScope = cmd.Flags().Bool("require-scope", rules.RequireScope, "require a scope")
+	maxHeader = cmd.Flags().Int("max-header", rules.MaxHeader, "maximum header length, 0 for no limit")
+	maxBodyLine = cmd.Flags().Int("max-line", rules.MaxBodyLine, "maximum body line length, 0 for no limit")
+	capitalSubject = cmd.Flags().Bool("capital-subject", rules.CapitalSubject, "require a capitalized subject")
+	breaking = cmd.Flags().Bool("require-breaking", rules.RequireBreaking,
+		"require a ! in the header and a BREAKING CHANGE footer")
+	return cmd
+}
+
+// tagPattern matches the timestamp tag line which starts the commit messages
+// written by yac, see timestamp.
+var tagPattern = regexp.MustCompile(`^\S+\.dev-\S+$`)
+
+// stripTag removes the leading timestamp tag line of a yac commit message.
+func stripTag(msg string) string {
+	first, rest, _ := strings.Cut(strings.TrimLeft(msg, "\n"), "\n")
+	if tagPattern.MatchString(strings.TrimSpace(first)) {
+		return rest
+	}
+	return msg
+}
+
+var (
+	errLint       = errors.New("commit message does not pass lint")
+	errLintSource = errors.New("more than one commit message")
+)
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name     string
+		msg      string
+		args     []string
+		expected string
+		err      error
+	}{
+		{
+			name: "stash",
+			msg:  "root.dev-202610181110.08\nfeat(cli): Add lint command\n\nChecks the stash.\n",
+		},
+		{
+			name:     "issues",
+			msg:      "root.dev-Sun.Oct.18.34PM\nfeat(cli): add lint command.\n",
+			expected: "1: subject-full-stop: the subject ends with a period\n1: subject-case: the subject does not start with a capital letter\n",
+			err:      errLint,
+		},
+		{
+			name:     "rules",
+			msg:      "wip: Add lint command",
+			args:     []string{"--types", "wip", "--require-scope"},
+			expected: "1: scope-required: the scope is missing\n",
+			err:      errLint,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			if err := os.WriteFile(path, []byte(test.msg), 0600); err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			cmd := NewCLI()
+			cmd.SetOut(&out)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{"lint", path}, test.args...))
+			if err := cmd.Execute(); !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v got %v", test.err, err)
+			}
+			if out.String() != test.expected {
+				t.Fatalf("expected\n%s\ngot\n%s", test.expected, out.String())
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	cmd := NewCLI()
+	cmd.SetOut(&out)
+	cmd.SetIn(strings.NewReader("fix: Handle stdin\n"))
+	cmd.SetArgs([]string{"lint", "-"})
+	if err := cmd.Execute(); err != nil || out.Len() != 0 {
+		t.Fatalf("unexpected stdin lint %v %q", err, out.String())
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/4sp1/yac/internal/provider"
+
+	"go.uber.org/zap"
+)
+
+// refine asks the provider to revise the commit message following
+// instruction, the conversation so far being sent along.
+func (cc *commitClient) refine(ctx context.Context, instruction string) error {
+	cc.turns = append(cc.turns,
+		provider.Message{Role: provider.RoleAssistant, Text: cc.commitBody},
+		provider.Message{Role: provider.RoleUser, Text: instruction})
+	if err := cc.complete(ctx); err != nil {
+		cc.turns = cc.turns[:len(cc.turns)-2]
+		return err
+	}
+	return nil
+}
+
+// refineLoop reads follow-up instructions on in until an empty line accepts
+// the commit message. A failed revision keeps the previous message.
+func (cc *commitClient) refineLoop(ctx context.Context, in *bufio.Reader, out io.Writer) error {
+	for {
+		if cc.stream == nil {
+			fmt.Fprintf(out, "\n%s\n", strings.TrimSpace(cc.commitBody))
+		}
+		fmt.Fprint(out, "\nrefine the message (empty to accept): ")
+		line, err := in.ReadString('\n')
+		instruction := strings.TrimSpace(line)
+		if instruction == "" {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("read instruction: %w", err)
+			}
+			fmt.Fprintln(out)
+			return nil
+		}
+		if err := cc.refine(ctx, instruction); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			cc.logger.Warn("refine failed, keeping the previous message", zap.Error(err))
+		}
+	}
+}
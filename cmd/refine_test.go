@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/4sp1/yac/internal/commit/message"
+	"github.com/4sp1/yac/internal/provider"
+
+	"go.uber.org/zap"
+)
+
+// reviser answers with the last instruction, and fails on "fail".
+type reviser struct {
+	requests []provider.Request
+}
+
+func (r *reviser) String() string { return "reviser" }
+
+func (r *reviser) Complete(ctx context.Context, req provider.Request) (provider.Response, error) {
+	r.requests = append(r.requests, req)
+	last := req.Messages[len(req.Messages)-1].Text
+	if last == "fail" {
+		return provider.Response{}, errors.New("fail")
+	}
+	return provider.Response{Text: "feat: " + last}, nil
+}
+
+func TestRefineLoop(t *testing.T) {
+	p := &reviser{}
+	cc := commitClient{
+		provider:   p,
+		userPrompt: "prompt",
+		commitBody: "feat: first",
+		logger:     zap.NewNop(),
+	}
+	in := bufio.NewReader(strings.NewReader("shorter\nfail\nmention the migration\n\n"))
+	if err := cc.refineLoop(context.Background(), in, io.Discard); err != nil {
+		t.Fatalf("refine loop: %s", err)
+	}
+	if cc.commitBody != "feat: mention the migration" {
+		t.Fatalf("unexpected commit body %q", cc.commitBody)
+	}
+	last := p.requests[len(p.requests)-1].Messages
+	var roles, texts []string
+	for _, m := range last {
+		roles = append(roles, m.Role)
+		texts = append(texts, m.Text)
+	}
+	if strings.Join(roles, ",") != "user,assistant,user,assistant,user" {
+		t.Fatalf("unexpected roles %q", roles)
+	}
+	if texts[1] != "feat: first" || texts[3] != "feat: shorter" {
+		t.Fatalf("unexpected turns %q", texts)
+	}
+}
+
+func TestRefineRepaired(t *testing.T) {
+	repo := newStagedRepo(t)
+	fixtures := filepath.Join(repo, ".yac", "fixtures")
+	const (
+		golden   = "feat(cli): Add main"
+		refined  = "feat(cli): add empty main"
+		repaired = "feat(cli): Add empty main"
+	)
+	writeFixture(t, fixtures, golden)
+	turns := []provider.Message{
+		{Role: provider.RoleAssistant, Text: golden},
+		{Role: provider.RoleUser, Text: "mention it is empty"},
+	}
+	writeTurnFixture(t, fixtures, turns, refined)
+	_, issues := message.DefaultRules.Lint(refined)
+	turns = append(turns,
+		provider.Message{Role: provider.RoleAssistant, Text: refined},
+		provider.Message{Role: provider.RoleUser, Text: repairInstruction(issues)})
+	writeTurnFixture(t, fixtures, turns, repaired)
+
+	cmd := newCommandClaudeCommit()
+	cmd.SetArgs([]string{"--jj=false", "--provider", "replay", "--fixtures", fixtures,
+		"--stdout=false", "--no-commit=false", "--refine"})
+	cmd.SetIn(strings.NewReader("mention it is empty\n\n"))
+	cmd.SetErr(io.Discard)
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("commit: %s", err)
+	}
+	_, body, _ := strings.Cut(git(t, "log", "-1", "--format=%B"), "\n")
+	if strings.TrimSpace(body) != repaired {
+		t.Fatalf("expected %q got %q", repaired, body)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/4sp1/yac/internal/commit/message"
+	"github.com/4sp1/yac/internal/provider"
+
+	"go.uber.org/zap"
+)
+
+// repair lints the commit message and, while it breaks the rules, sends the
+// issues back to the provider as a follow-up turn, at most attempts times.
+// When every attempt fails the lint, the one with the fewest issues is kept
+// and its issues are returned.
+func (cc *commitClient) repair(ctx context.Context, rules message.Rules, attempts int) ([]message.Issue, error) {
+	type attempt struct {
+		response    provider.Response
+		temperature float64
+		latency     time.Duration
+		turns       int
+		issues      []message.Issue
+	}
+	// the attempts are not streamed, the kept one is printed at last
+	stream := cc.stream
+	cc.stream = nil
+	defer func() { cc.stream = stream }()
+	var best attempt
+	for i := 0; ; i++ {
+		_, issues := rules.Lint(cc.commitBody)
+		if len(issues) == 0 {
+			return nil, nil
+		}
+		if i == 0 || len(issues) < len(best.issues) {
+			best = attempt{cc.response, cc.temperature, cc.latency, len(cc.turns), issues}
+		}
+		if i == attempts {
+			break
+		}
+		cc.logger.Debug("repair commit message",
+			zap.Int("attempt", i+1), zap.Int("issues", len(issues)))
+		cc.turns = append(cc.turns,
+			provider.Message{Role: provider.RoleAssistant, Text: cc.commitBody},
+			provider.Message{Role: provider.RoleUser, Text: repairInstruction(issues)})
+		if err := cc.complete(ctx); err != nil {
+			cc.turns = cc.turns[:len(cc.turns)-2]
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			cc.logger.Warn("repair failed", zap.Error(err))
+			break
+		}
+	}
+	cc.response, cc.temperature, cc.latency = best.response, best.temperature, best.latency
+	cc.turns = cc.turns[:best.turns]
+	cc.commitBody = cc.response.Text
+	return best.issues, nil
+}
+
+// repairInstruction asks for a commit message fixing the lint issues.
+func repairInstruction(issues []message.Issue) string {
+	var b strings.Builder
+	b.WriteString("The commit message breaks these rules (line: rule: issue):\n\n")
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "- %s\n", issue)
+	}
+	b.WriteString("\nRewrite it so that it follows every rule. " +
+		"Output ONLY the corrected commit message, with no preamble and no code fence.")
+	return b.String()
+}
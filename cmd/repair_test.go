@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/4sp1/yac/internal/commit/message"
+	"github.com/4sp1/yac/internal/provider"
+
+	"go.uber.org/zap"
+)
+
+// scripted answers with its texts in order, the last one being repeated.
+type scripted struct {
+	texts    []string
+	requests []provider.Request
+}
+
+func (s *scripted) String() string { return "scripted" }
+
+func (s *scripted) Complete(ctx context.Context, req provider.Request) (provider.Response, error) {
+	s.requests = append(s.requests, req)
+	text := s.texts[min(len(s.requests), len(s.texts))-1]
+	return provider.Response{Text: text}, nil
+}
+
+func TestRepair(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		texts    []string
+		attempts int
+		expected string
+		issues   int
+		requests int
+	}{
+		{
+			name:     "valid",
+			texts:    []string{"feat: Add pagination"},
+			attempts: 2,
+			expected: "feat: Add pagination",
+			requests: 1,
+		},
+		{
+			name:     "repaired",
+			texts:    []string{"Here's the commit message:\n\nfeat: Add pagination", "feat: Add pagination"},
+			attempts: 2,
+			expected: "feat: Add pagination",
+			requests: 2,
+		},
+		{
+			name: "best attempt",
+			texts: []string{
+				"feature: add pagination.",
+				"feat: add pagination",
+				"Here's the commit message:\n\nfeat: add pagination.",
+			},
+			attempts: 2,
+			expected: "feat: add pagination",
+			issues:   1,
+			requests: 3,
+		},
+		{
+			name: "code block",
+			texts: []string{"feat: Add pagination\n\nList a page:\n\n```\n" +
+				"client.List(ctx, api.Page{Size: 100, Token: next}, api.WithRetries(3), api.WithTimeout(time.Minute))\n```"},
+			attempts: 2,
+			expected: "feat: Add pagination\n\nList a page:\n\n```\n" +
+				"client.List(ctx, api.Page{Size: 100, Token: next}, api.WithRetries(3), api.WithTimeout(time.Minute))\n```",
+			requests: 1,
+		},
+		{
+			name:     "disabled",
+			texts:    []string{"feat: add pagination"},
+			expected: "feat: add pagination",
+			issues:   1,
+			requests: 1,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := &scripted{texts: test.texts}
+			cc := commitClient{provider: p, userPrompt: "prompt", logger: zap.NewNop()}
+			ctx := context.Background()
+			if err := cc.complete(ctx); err != nil {
+				t.Fatalf("complete: %s", err)
+			}
+			issues, err := cc.repair(ctx, message.DefaultRules, test.attempts)
+			if err != nil {
+				t.Fatalf("repair: %s", err)
+			}
+			if cc.commitBody != test.expected || len(issues) != test.issues {
+				t.Fatalf("unexpected repair %q %v", cc.commitBody, issues)
+			}
+			if len(p.requests) != test.requests {
+				t.Fatalf("expected %d requests got %d", test.requests, len(p.requests))
+			}
+		})
+	}
+
+	p := &scripted{texts: []string{"feat: add pagination", "feat: Add pagination"}}
+	cc := commitClient{provider: p, userPrompt: "prompt", logger: zap.NewNop()}
+	if err := cc.complete(context.Background()); err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if _, err := cc.repair(context.Background(), message.DefaultRules, 1); err != nil {
+		t.Fatalf("repair: %s", err)
+	}
+	last := p.requests[1].Messages
+	if len(last) != 3 || !strings.Contains(last[2].Text, "subject-case") {
+		t.Fatalf("unexpected repair request %+v", last)
+	}
+}
+
+func TestRepairNotStreamed(t *testing.T) {
+	var stream strings.Builder
+	p := &scripted{texts: []string{"feat: add pagination", "feat: Add pagination"}}
+	cc := commitClient{provider: p, userPrompt: "prompt", stream: &stream, logger: zap.NewNop()}
+	if err := cc.complete(context.Background()); err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if _, err := cc.repair(context.Background(), message.DefaultRules, 1); err != nil {
+		t.Fatalf("repair: %s", err)
+	}
+	if cc.stream == nil || cc.streamed != "feat: add pagination" || cc.commitBody != "feat: Add pagination" {
+		t.Fatalf("unexpected stream state %q %q", cc.streamed, cc.commitBody)
+	}
+	for _, req := range p.requests {
+		if req.Stream != nil && len(req.Messages) > 1 {
+			t.Fatalf("repair turn streamed")
+		}
+	}
+}
+
+func TestRevisedAfterStream(t *testing.T) {
+	p := &scripted{texts: []string{"feat: Add pagination  \n\nThe users endpoint previously fetched all records " +
+		"without pagination, causing slow responses."}}
+	cc := commitClient{provider: p, userPrompt: "prompt", stream: io.Discard, logger: zap.NewNop()}
+	if err := cc.complete(context.Background()); err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if cc.revised() {
+		t.Fatal("unexpected revision before formatting")
+	}
+	cc.commitBody = message.Format(cc.commitBody, message.DefaultRules.MaxBodyLine)
+	if !cc.revised() {
+		t.Fatal("the formatted message must be printed again")
+	}
+}
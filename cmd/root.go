@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"bytes"
 	_ "embed"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -20,7 +19,9 @@ import (
 var srcDir = os.Getenv("YAG_SRCDIR")
 
 func NewCLI() *cobra.Command {
-	return newCommandClaudeCommit()
+	cmd := newCommandClaudeCommit()
+	cmd.AddCommand(newCommandHistory(), newCommandLint())
+	return cmd
 }
 
 func NewLegacyCLI() *cobra.Command {
@@ -365,7 +366,9 @@ func NewLegacyCLI() *cobra.Command {
 		installCmd,
 		skCmd,
 		claudeCmd,
-		uCmd)
+		uCmd,
+		newCommandHistory(),
+		newCommandLint())
 
 	claudeCmd.AddCommand(newCommandClaudeCommit())
 
@@ -548,46 +551,6 @@ func (tsf tstampFormat) print() error {
 	return nil
 }
 
-type claudeMsg struct {
-	Role    string             `json:"role"`
-	Content []claudeMsgContent `json:"content"`
-}
-
-type claudeMsgContent interface {
-	fmt.Stringer
-	json.Marshaler
-}
-
-func newClaudeMsgTxt(text string) claudeMsgContent {
-	return claudeMsgTxt{
-		Type: "text",
-		Text: text,
-	}
-}
-
-func (c claudeMsgTxt) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
-	}{
-		Type: c.Type,
-		Text: c.Text,
-	})
-}
-
-func (cmc claudeMsgTxt) String() string {
-	var b bytes.Buffer
-	if err := json.NewEncoder(&b).Encode(cmc); err != nil {
-		panic(err)
-	}
-	return b.String()
-}
-
-type claudeMsgTxt struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
-
 var red = func(s string) string {
 	return fmt.Sprintf("\033[31m%s\033[0m", s)
 }
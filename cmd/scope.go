@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/4sp1/yac/internal/commit/message"
+	"github.com/4sp1/yac/internal/commit/scope"
+)
+
+// scopeRules adds the sc scope to the lint rules, where it is required.
+func scopeRules(rules message.Rules, sc scope.Scope) message.Rules {
+	if sc == scope.Other {
+		return rules
+	}
+	rules.Scopes = []string{sc.Name()}
+	rules.RequireScope = true
+	return rules
+}
+
+// enforceScope writes the sc scope in the header of the commit message,
+// whatever scope the model chose. It fails when the header cannot hold a
+// scope.
+func enforceScope(body string, sc scope.Scope) (string, error) {
+	if sc == scope.Other {
+		return body, nil
+	}
+	m, err := message.Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("scope %q: %w", sc.Name(), err)
+	}
+	if m.Scope == sc.Name() {
+		return body, nil
+	}
+	m.Scope = sc.Name()
+	_, rest, found := strings.Cut(strings.TrimLeft(body, " \t\r\n"), "\n")
+	if !found {
+		return m.FormatHeader(), nil
+	}
+	return m.FormatHeader() + "\n" + rest, nil
+}
+
+// scopedHeaderIssues lints the header the scope was written in, which may now
+// be too long.
+func scopedHeaderIssues(body string) []message.Issue {
+	header, _, _ := strings.Cut(body, "\n")
+	_, issues := message.Rules{MaxHeader: message.DefaultRules.MaxHeader}.Lint(header)
+	return issues
+}
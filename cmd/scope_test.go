@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/4sp1/yac/internal/agent"
+	"github.com/4sp1/yac/internal/history"
+
+	"github.com/4sp1/yac/internal/commit/message"
+	"github.com/4sp1/yac/internal/commit/scope"
+)
+
+func TestEnforceScope(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		body     string
+		scope    scope.Scope
+		expected string
+		err      error
+	}{
+		{
+			name:     "no scope",
+			body:     "feat(api): Add pagination\n",
+			scope:    scope.Other,
+			expected: "feat(api): Add pagination\n",
+		},
+		{
+			name:     "rewritten",
+			body:     "feat(api)!: Add pagination\n\nBody.\n",
+			scope:    scope.Backend,
+			expected: "feat(backend)!: Add pagination\n\nBody.\n",
+		},
+		{
+			name:     "added",
+			body:     "\nfix: Handle empty diff",
+			scope:    scope.Cli,
+			expected: "fix(cli): Handle empty diff",
+		},
+		{
+			name:     "type",
+			body:     "feat(docs): Add usage",
+			scope:    scope.Docs,
+			expected: "feat(docs): Add usage",
+		},
+		{
+			name:  "header",
+			body:  "Add usage",
+			scope: scope.Docs,
+			err:   message.ErrHeader,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := enforceScope(test.body, test.scope)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v got %v", test.err, err)
+			}
+			if got != test.expected {
+				t.Fatalf("expected %q got %q", test.expected, got)
+			}
+		})
+	}
+
+	rules := scopeRules(message.DefaultRules, scope.Docs)
+	if _, issues := rules.Lint("feat: Add usage"); len(issues) != 1 ||
+		issues[0].Rule != "scope-required" {
+		t.Fatalf("unexpected issues %v", issues)
+	}
+	issues := scopedHeaderIssues("feat(release-candidate): Add an empty main entry point\n\nBody.")
+	if len(issues) != 1 || issues[0].Rule != "header-max-length" {
+		t.Fatalf("unexpected header issues %v", issues)
+	}
+}
+
+func TestCommitScope(t *testing.T) {
+	repo := newStagedRepo(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	fixtures := filepath.Join(repo, ".yac", "fixtures")
+	const golden = "feat(cli): Add an empty main entry point"
+	for _, test := range []struct {
+		scope    scope.Scope
+		expected string
+		warning  string
+	}{
+		{scope: scope.Api, expected: "feat(api): Add an empty main entry point"},
+		// the scope may push the header past its limit
+		{
+			scope:    scope.ReleaseCandidate,
+			expected: "feat(release-candidate): Add an empty main entry point",
+			warning:  "header-max-length",
+		},
+	} {
+		// each run commits its own change
+		name := test.scope.Name() + ".go"
+		if err := os.WriteFile(name, []byte("package main\n"), 0600); err != nil {
+			t.Fatalf("write %s: %s", name, err)
+		}
+		git(t, "add", name)
+		writeFixture(t, fixtures, golden, agent.WithScope(test.scope))
+		var stderr bytes.Buffer
+		cmd := newCommandClaudeCommit()
+		cmd.SetErr(&stderr)
+		cmd.SetArgs([]string{
+			"--jj=false", "--provider", "replay", "--fixtures", fixtures,
+			"--stdout=false", "--no-commit=false", "--history", "--" + test.scope.Flag(),
+		})
+		if err := cmd.ExecuteContext(context.Background()); err != nil {
+			t.Fatalf("commit: %s", err)
+		}
+		b, err := os.ReadFile(".commit-stash")
+		if err != nil {
+			t.Fatalf("read stash: %s", err)
+		}
+		if got := strings.TrimSpace(stripTag(string(b))); got != test.expected {
+			t.Fatalf("expected %q got %q", test.expected, got)
+		}
+		if !strings.Contains(stderr.String(), test.warning) {
+			t.Fatalf("expected warning %q got %q", test.warning, stderr.String())
+		}
+	}
+
+	dir, err := historyDir("")
+	if err != nil {
+		t.Fatalf("history dir: %s", err)
+	}
+	entries, err := history.List(dir)
+	if err != nil {
+		t.Fatalf("list: %s", err)
+	}
+	if len(entries) != 2 || entries[0].Subject() != "feat(api): Add an empty main entry point" {
+		t.Fatalf("expected the scoped message to be archived, got %+v", entries)
+	}
+}
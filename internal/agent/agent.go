@@ -2,6 +2,7 @@ package agent
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 
@@ -12,7 +13,8 @@ import (
 
 func New(opts ...Option) (Agent, error) {
 	ctx := AgentContext{
-		wip: make(map[wip.Context][]string),
+		wip:    make(map[wip.Context][]string),
+		system: systemDoc,
 	}
 	var err error
 	for _, opt := range opts {
@@ -27,15 +29,34 @@ func New(opts ...Option) (Agent, error) {
 }
 
 type Agent interface {
+	SystemPrompt() (string, error)
 	UserPrompt() (string, error)
+	MapReduce(ctx context.Context, split Split, jobs int, summarize Summarize) (Agent, error)
+	// BreakingChanges lists the possible breaking changes of the diff.
+	BreakingChanges() []BreakingChange
 }
 
 type AgentContext struct {
+	// diff is the whole staged diff, only shrunk or summarized when it is
+	// rendered, so that breakingChanges scans all of it.
 	diff  string
 	scope string
 	logs  []string
 	wip   map[wip.Context][]string
 
+	template string
+	// system is the system prompt template, empty when the user template
+	// carries the rules itself.
+	system string
+	// budget bounds the prompt tokens, 0 for no bound.
+	budget int
+	// summaries replace the diff once it was map-reduced.
+	summaries []Summary
+	// examples are the conventional commits of the history, picked from
+	// according to examplesCfg.
+	examples    []example
+	examplesCfg Examples
+
 	logger *zap.Logger
 }
 
@@ -43,8 +64,8 @@ type agent struct {
 	context AgentContext
 }
 
-func (a agent) UserPrompt() (string, error) {
-	var b bytes.Buffer
+func (a agent) filler() templateFiller {
+	logs := strings.TrimSpace(strings.Join(a.context.logs, "\n\n"))
 	ideal := []idealSection{}
 	for i := wip.Other; i < wip.UpperBound; i++ {
 		if notes, ok := a.context.wip[i]; ok {
@@ -57,14 +78,79 @@ func (a agent) UserPrompt() (string, error) {
 			})
 		}
 	}
-	err := templateFiller{
-		GitLog:         strings.TrimSpace(strings.Join(a.context.logs, "\n\n")),
+	diff := a.context.diff
+	if len(a.context.summaries) > 0 {
+		diff = ""
+		if _, status, ok := strings.Cut(a.context.diff, statusSeparator); ok {
+			diff = strings.TrimPrefix(statusSeparator, "\n\n") + status
+		}
+	}
+	diff, elided := fitDiff(diff, a.diffBudget(logs, ideal))
+	var breaking []string
+	for _, c := range a.BreakingChanges() {
+		breaking = append(breaking, c.String())
+	}
+	return templateFiller{
+		GitLog:         logs,
 		Scope:          a.context.scope,
-		Diff:           a.context.diff,
+		Diff:           diff,
+		Elided:         elided,
+		Summaries:      a.context.summaries,
+		Examples:       a.pickExamples(),
+		Breaking:       breaking,
 		IdealFuture:    ideal,
 		IdealSeparator: DefaultIdealSeparator,
-	}.Fill(&b)
-	if err != nil {
+	}
+}
+
+// diffBudget is what remains of the token budget for the diff once the
+// templates, logs and notes are accounted for.
+func (a agent) diffBudget(logs string, ideal []idealSection) int {
+	if a.context.budget <= 0 {
+		return 0
+	}
+	doc := a.context.template
+	if doc == "" {
+		doc = templateDoc
+	}
+	rest := estimateTokens(doc) + estimateTokens(a.context.system) +
+		estimateTokens(logs) + elisionReserve
+	for _, s := range ideal {
+		for _, n := range s.Notes {
+			rest += estimateTokens(n)
+		}
+	}
+	// a budget too small for the templates still gets the diff cut rather
+	// than left whole
+	return max(a.context.budget-rest, 1)
+}
+
+func (a agent) UserPrompt() (string, error) {
+	tf := a.filler()
+	if len(tf.Elided) > 0 && a.context.logger != nil {
+		a.context.logger.Info("diff elided to fit the token budget",
+			zap.Int("budget", a.context.budget),
+			zap.Strings("elided", tf.Elided))
+	}
+	var b bytes.Buffer
+	if err := tf.Fill(&b, withDoc(a.context.template)); err != nil {
+		return "", fmt.Errorf("templateFiller: %w", err)
+	}
+	return b.String(), nil
+}
+
+func (a agent) BreakingChanges() []BreakingChange {
+	return breakingChanges(a.context.diff)
+}
+
+// SystemPrompt returns the rules the model must follow, meant for the
+// provider system slot. It is empty when the user prompt carries them.
+func (a agent) SystemPrompt() (string, error) {
+	if a.context.system == "" {
+		return "", nil
+	}
+	var b bytes.Buffer
+	if err := a.filler().Fill(&b, withDoc(a.context.system)); err != nil {
 		return "", fmt.Errorf("templateFiller: %w", err)
 	}
 	return b.String(), nil
@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// BreakingChange is a change of the diff which possibly breaks the users of
+// the code: a removed exported Go identifier, a changed function signature
+// or type definition, or a removed command line flag.
+type BreakingChange struct {
+	Path string
+	// Kind is func, method, type, var/const or flag.
+	Kind   string
+	Name   string
+	Detail string
+}
+
+func (c BreakingChange) String() string {
+	return fmt.Sprintf("%s: %s %s %s", c.Path, c.Kind, c.Name, c.Detail)
+}
+
+var (
+	funcDecl = regexp.MustCompile(
+		`^func\s+(?:\(\s*(?:\w+\s+)?\*?\s*(\w+)(?:\[[^\]]*\])?\s*\)\s*)?([A-Z]\w*)\s*[\[(]`)
+	typeDecl  = regexp.MustCompile(`^type\s+([A-Z]\w*)\b`)
+	valueDecl = regexp.MustCompile(`^(?:var|const)\s+([A-Z]\w*)\b`)
+	// groupDecl is an exported var or const of a var ( ... ) or const ( ... )
+	// block, struct fields do not have the = sign.
+	groupDecl = regexp.MustCompile(`^\t([A-Z]\w*)(?:\s+[\w.*\[\]]+)?\s+=\s`)
+	flagDecl  = regexp.MustCompile(`(?:Flags\(\)|\bflag)\.\w+\((?:&?[\w.]+,\s*)?"([^"]+)"`)
+)
+
+// declaration is an exported Go declaration of a diff line.
+type declaration struct {
+	key  string // package directory, kind, receiver and name
+	kind string
+	name string
+	// text is the declaration up to its body, compared to tell signature
+	// and definition changes apart from moves.
+	text string
+}
+
+// parseDeclaration returns the exported declaration of a Go source line.
+func parseDeclaration(dir, line string) (declaration, bool) {
+	d := declaration{text: normalizeDeclaration(line)}
+	if m := funcDecl.FindStringSubmatch(line); m != nil {
+		d.kind, d.name = "func", m[2]
+		if m[1] != "" {
+			if !isExported(m[1]) {
+				return d, false
+			}
+			d.kind, d.name = "method", m[1]+"."+m[2]
+		}
+	} else if m := typeDecl.FindStringSubmatch(line); m != nil {
+		d.kind, d.name = "type", m[1]
+	} else if m := valueDecl.FindStringSubmatch(line); m != nil {
+		d.kind, d.name, d.text = "var/const", m[1], ""
+	} else if m := groupDecl.FindStringSubmatch(line); m != nil {
+		d.kind, d.name, d.text = "var/const", m[1], ""
+	} else {
+		return d, false
+	}
+	d.key = dir + " " + d.kind + " " + d.name
+	return d, true
+}
+
+// normalizeDeclaration drops the trailing comment, the opening brace and the
+// spacing of a declaration so that only changes to its code are compared.
+func normalizeDeclaration(line string) string {
+	line, _, _ = strings.Cut(line, "//")
+	line = strings.TrimSuffix(strings.TrimSpace(line), "{")
+	line = strings.Join(strings.Fields(line), " ")
+	line = strings.ReplaceAll(line, "( ", "(")
+	return strings.ReplaceAll(line, " )", ")")
+}
+
+func isExported(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// publicGoFile tells whether the exported identifiers of a Go file are
+// reachable from outside the module.
+func publicGoFile(p string) bool {
+	if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+		return false
+	}
+	for _, elem := range strings.Split(path.Dir(p), "/") {
+		if elem == "internal" || elem == "testdata" || elem == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+// breakingChanges scans the Go files of diff for exported identifiers removed
+// or changed, and for command line flags removed. A declaration removed from
+// a file and added back unchanged in another file of the same package is a
+// move, not a breaking change.
+func breakingChanges(diff string) []BreakingChange {
+	body, _, _ := strings.Cut(diff, statusSeparator)
+	_, files := parseDiff(body)
+
+	type removal struct {
+		path string
+		declaration
+	}
+	var (
+		removed      []removal
+		removedFlags []BreakingChange
+		added        = map[string][]string{}
+		addedFlags   = map[string]bool{}
+	)
+	for _, f := range files {
+		if !strings.HasSuffix(f.path, ".go") || strings.HasSuffix(f.path, "_test.go") {
+			continue
+		}
+		public := publicGoFile(f.path)
+		for _, hunk := range f.hunks {
+			for _, l := range strings.Split(hunk, "\n") {
+				if l == "" || (l[0] != '+' && l[0] != '-') {
+					continue
+				}
+				sign, line := l[0], l[1:]
+				for _, m := range flagDecl.FindAllStringSubmatch(line, -1) {
+					if sign == '+' {
+						addedFlags[m[1]] = true
+					} else {
+						removedFlags = append(removedFlags, BreakingChange{
+							Path: f.path, Kind: "flag", Name: "--" + m[1], Detail: "removed",
+						})
+					}
+				}
+				if !public {
+					continue
+				}
+				d, ok := parseDeclaration(path.Dir(f.path), line)
+				if !ok {
+					continue
+				}
+				if sign == '+' {
+					added[d.key] = append(added[d.key], d.text)
+				} else {
+					removed = append(removed, removal{f.path, d})
+				}
+			}
+		}
+	}
+
+	var changes []BreakingChange
+	add := func(c BreakingChange) {
+		if !slices.Contains(changes, c) {
+			changes = append(changes, c)
+		}
+	}
+	for _, r := range removed {
+		texts, ok := added[r.key]
+		switch {
+		case !ok:
+			add(BreakingChange{Path: r.path, Kind: r.kind, Name: r.name, Detail: "removed"})
+		case !slices.Contains(texts, r.text):
+			detail := "definition changed"
+			if r.kind == "func" || r.kind == "method" {
+				detail = "signature changed"
+			}
+			add(BreakingChange{Path: r.path, Kind: r.kind, Name: r.name,
+				Detail: fmt.Sprintf("%s from `%s` to `%s`", detail, r.text, texts[0])})
+		}
+	}
+	for _, c := range removedFlags {
+		if !addedFlags[strings.TrimPrefix(c.Name, "--")] {
+			add(c)
+		}
+	}
+	return changes
+}
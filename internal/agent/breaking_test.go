@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	diff := `diff --git a/client/client.go b/client/client.go
+index 1111111..2222222 100644
+--- a/client/client.go
++++ b/client/client.go
+@@ -1,20 +1,18 @@
+ package client
+ 
+-func New(url string) *Client {
++func New(url string, timeout time.Duration) *Client {
+ 	return &Client{url: url}
+ }
+ 
+-func (c *Client) Get(path string) error {
++func (c *Client) Get(path string) error {
+ 	return nil
+ }
+ 
+-func Foo(a int) error {
++func Foo(a int) error { // doc
+ 	return nil
+ }
+ 
+-func (c *Client) Do(req *Request) error {
++func (c *Client) Do( req *Request ) error {
+ 	return nil
+ }
+ 
+-func (c *Client) Close() {}
+-func (c *client) Reset() {}
+-type Option func(*Client)
+-const DefaultTimeout = time.Second
++const DefaultTimeout = 2 * time.Second
+ var (
+-	ErrClosed = errors.New("closed")
+ 	errBusy = errors.New("busy")
+ )
+diff --git a/client/moved.go b/client/moved.go
+new file mode 100644
+--- /dev/null
++++ b/client/moved.go
+@@ -0,0 +1,3 @@
++package client
++
++type Option func(*Client)
+diff --git a/internal/store/store.go b/internal/store/store.go
+--- a/internal/store/store.go
++++ b/internal/store/store.go
+@@ -1,3 +1,2 @@
+ package store
+-func Open() {}
+diff --git a/cmd/root.go b/cmd/root.go
+--- a/cmd/root.go
++++ b/cmd/root.go
+@@ -1,4 +1,3 @@
+-	verbose = cmd.Flags().Bool("verbose", false, "verbose output")
+-	dir = cmd.PersistentFlags().String("dir", "", "work directory")
++	dir = cmd.PersistentFlags().StringP("dir", "d", "", "work directory")
+diff --git a/client/client_test.go b/client/client_test.go
+--- a/client/client_test.go
++++ b/client/client_test.go
+@@ -1,2 +1,1 @@
+-func TestNew(t *testing.T) {}
+` + statusSeparator + "M  client/client.go\n"
+
+	var got []string
+	for _, c := range breakingChanges(diff) {
+		got = append(got, c.String())
+	}
+	expected := []string{
+		"client/client.go: func New signature changed from `func New(url string) *Client` " +
+			"to `func New(url string, timeout time.Duration) *Client`",
+		"client/client.go: method Client.Close removed",
+		"client/client.go: var/const ErrClosed removed",
+		"cmd/root.go: flag --verbose removed",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestAgentBreakingChanges(t *testing.T) {
+	a, err := New()
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	a.(*agent).context.diff = "diff --git a/api.go b/api.go\n--- a/api.go\n+++ b/api.go\n" +
+		"@@ -1,2 +1,1 @@\n package api\n-func Serve() {}\n"
+	prompt, err := a.UserPrompt()
+	if err != nil {
+		t.Fatalf("user prompt: %s", err)
+	}
+	if !strings.Contains(prompt, "<breaking_changes>\n- api.go: func Serve removed\n</breaking_changes>") {
+		t.Fatalf("breaking changes missing from the prompt\n%s", prompt)
+	}
+}
+
+func TestBreakingChangesOfShrunkDiff(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("diff --git a/api.go b/api.go\n--- a/api.go\n+++ b/api.go\n@@ -1,400 +1,1 @@\n package api\n")
+	for i := range 400 {
+		fmt.Fprintf(&b, "-func Serve%d() {}\n", i)
+	}
+	a, err := New(WithTokenBudget(estimateTokens(templateDoc+systemDoc) + elisionReserve + 50))
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	a.(*agent).context.diff = b.String()
+	if tf := a.(*agent).filler(); len(tf.Elided) == 0 || len(tf.Breaking) != 400 {
+		t.Fatalf("expected the breaking changes of the elided diff, got %d", len(tf.Breaking))
+	}
+	reduced, err := a.MapReduce(context.Background(), SplitFile, 1,
+		func(context.Context, string) (string, error) { return "summary", nil })
+	if err != nil {
+		t.Fatalf("map reduce: %s", err)
+	}
+	if n := len(reduced.BreakingChanges()); n != 400 {
+		t.Fatalf("expected the breaking changes of the summarized diff, got %d", n)
+	}
+}
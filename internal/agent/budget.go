@@ -0,0 +1,254 @@
+package agent
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// bytesPerToken is a rough average for English and code, good enough to
+// budget a prompt without the model tokenizer.
+const bytesPerToken = 4
+
+// elisionReserve keeps room, in tokens, for the note listing the elided parts.
+const elisionReserve = 256
+
+// statusSeparator sits between the diff and the vcs status in the diff
+// context.
+const statusSeparator = "\n\n----------------\n\ngit status -s\n\n"
+
+func estimateTokens(s string) int {
+	return (len(s) + bytesPerToken - 1) / bytesPerToken
+}
+
+// elision says how much of a file diff is left in the prompt.
+type elision int
+
+const (
+	elideNone elision = iota
+	// elideHunks keeps hunk headers and the changed lines declaring something.
+	elideHunks
+	// elideStat keeps the file path and its changed lines count.
+	elideStat
+)
+
+// fileDiff is the part of a unified diff about a single file.
+type fileDiff struct {
+	path string
+	// header goes from the diff --git line to the first hunk.
+	header  string
+	hunks   []string
+	added   int
+	removed int
+	binary  bool
+
+	elision elision
+}
+
+var (
+	generatedMarker = regexp.MustCompile(`(?m)^\+.*Code generated .* DO NOT EDIT\.`)
+	signatureLine   = regexp.MustCompile(`^[+-]\s*(export\s+|pub\s+|public\s+|private\s+|async\s+)*` +
+		`(func|type|class|def|fn|interface|struct|enum|trait|impl|package|module)\b`)
+	lockFiles = []string{
+		"go.sum", "go.work.sum", "package-lock.json", "npm-shrinkwrap.json",
+		"yarn.lock", "pnpm-lock.yaml", "bun.lockb", "Cargo.lock", "poetry.lock",
+		"uv.lock", "Pipfile.lock", "Gemfile.lock", "composer.lock", "flake.lock",
+	}
+	generatedDirs     = []string{"vendor", "node_modules", "third_party"}
+	generatedSuffixes = []string{".pb.go", ".pb.gw.go", "_gen.go", ".min.js", ".min.css", ".map"}
+)
+
+// generated reports lockfiles, vendored dependencies, generated code and
+// binaries, the first to go when the diff exceeds its budget.
+func (f fileDiff) generated() bool {
+	if f.binary || slices.Contains(lockFiles, path.Base(f.path)) {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(f.path), "/") {
+		if slices.Contains(generatedDirs, dir) {
+			return true
+		}
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(f.path, suffix) {
+			return true
+		}
+	}
+	for _, h := range f.hunks {
+		if generatedMarker.MatchString(h) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fileDiff) stat() string {
+	return fmt.Sprintf("%s | +%d -%d", f.path, f.added, f.removed)
+}
+
+func (f fileDiff) String() string {
+	var b strings.Builder
+	switch f.elision {
+	case elideNone:
+		b.WriteString(f.header)
+		for _, h := range f.hunks {
+			b.WriteString(h)
+		}
+	case elideHunks:
+		b.WriteString(f.header)
+		for _, h := range f.hunks {
+			head, body, _ := strings.Cut(h, "\n")
+			b.WriteString(head + "\n")
+			var elided int
+			for _, l := range strings.SplitAfter(body, "\n") {
+				switch {
+				case l == "":
+				case signatureLine.MatchString(l):
+					b.WriteString(l)
+				default:
+					elided++
+				}
+			}
+			if elided > 0 {
+				fmt.Fprintf(&b, "[... %d lines elided]\n", elided)
+			}
+		}
+	case elideStat:
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n[elided: %s]\n", f.path, f.path, f.stat())
+	}
+	return b.String()
+}
+
+func (f fileDiff) note() string {
+	switch f.elision {
+	case elideHunks:
+		return fmt.Sprintf("%s: hunks collapsed to their signatures (+%d -%d)",
+			f.path, f.added, f.removed)
+	case elideStat:
+		reason := "too large"
+		if f.generated() {
+			reason = "generated"
+		}
+		return fmt.Sprintf("%s: %s, only its stat is kept (+%d -%d)",
+			f.path, reason, f.added, f.removed)
+	}
+	return ""
+}
+
+// parseDiff splits a unified diff in the text preceding the first file and
+// the file diffs.
+func parseDiff(diff string) (string, []fileDiff) {
+	var (
+		preamble strings.Builder
+		files    []fileDiff
+	)
+	for _, l := range strings.SplitAfter(diff, "\n") {
+		if l == "" {
+			continue
+		}
+		if strings.HasPrefix(l, "diff --git ") {
+			p := strings.TrimSpace(l)
+			if _, b, ok := strings.Cut(p, " b/"); ok {
+				p = b
+			}
+			files = append(files, fileDiff{path: p, header: l})
+			continue
+		}
+		if len(files) == 0 {
+			preamble.WriteString(l)
+			continue
+		}
+		f := &files[len(files)-1]
+		if strings.HasPrefix(l, "@@") {
+			f.hunks = append(f.hunks, l)
+			continue
+		}
+		if len(f.hunks) == 0 {
+			if strings.HasPrefix(l, "Binary files ") || strings.HasPrefix(l, "GIT binary patch") {
+				f.binary = true
+			}
+			f.header += l
+			continue
+		}
+		switch l[0] {
+		case '+':
+			f.added++
+		case '-':
+			f.removed++
+		}
+		f.hunks[len(f.hunks)-1] += l
+	}
+	return preamble.String(), files
+}
+
+// fitDiff shrinks diff to about budget tokens: generated files are reduced to
+// their stat first, then the largest files have their hunks collapsed to the
+// signatures they touch, then are reduced to their stat, and as a last resort
+// the diff is cut. The returned notes describe what was elided.
+func fitDiff(diff string, budget int) (string, []string) {
+	if budget <= 0 || estimateTokens(diff) <= budget {
+		return diff, nil
+	}
+	body, status, hasStatus := strings.Cut(diff, statusSeparator)
+	preamble, files := parseDiff(body)
+
+	size := len(diff)
+	fits := func() bool { return size <= budget*bytesPerToken }
+	elide := func(f *fileDiff, e elision) {
+		before := len(f.String())
+		f.elision = e
+		size += len(f.String()) - before
+	}
+
+	for i := range files {
+		if files[i].generated() {
+			elide(&files[i], elideStat)
+		}
+	}
+	sizes := make([]int, len(files))
+	bySize := make([]int, len(files))
+	for i := range files {
+		sizes[i] = len(files[i].String())
+		bySize[i] = i
+	}
+	slices.SortStableFunc(bySize, func(a, b int) int {
+		return cmp.Compare(sizes[b], sizes[a])
+	})
+	for _, e := range []elision{elideHunks, elideStat} {
+		for _, i := range bySize {
+			if fits() {
+				break
+			}
+			if files[i].elision < e {
+				elide(&files[i], e)
+			}
+		}
+	}
+
+	var (
+		b     strings.Builder
+		notes []string
+	)
+	b.WriteString(preamble)
+	for _, f := range files {
+		b.WriteString(f.String())
+		if n := f.note(); n != "" {
+			notes = append(notes, n)
+		}
+	}
+	out := b.String()
+	if hasStatus {
+		out += statusSeparator + status
+	}
+	if limit := budget * bytesPerToken; len(out) > limit {
+		out = out[:limit]
+		if i := strings.LastIndexByte(out, '\n'); i > 0 {
+			out = out[:i+1]
+		}
+		notes = append(notes, fmt.Sprintf("the diff was cut after %d bytes", len(out)))
+	}
+	return out, notes
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testDiff() string {
+	var b strings.Builder
+	b.WriteString("diff --git a/go.sum b/go.sum\nindex 1..2 100644\n--- a/go.sum\n+++ b/go.sum\n@@ -1,1 +1,200 @@\n")
+	for i := range 200 {
+		fmt.Fprintf(&b, "+example.com/mod%d v1.0.0 h1:abcdefghijklmnopqrstuvwxyz=\n", i)
+	}
+	b.WriteString("diff --git a/server.go b/server.go\nindex 1..2 100644\n--- a/server.go\n+++ b/server.go\n@@ -10,3 +10,80 @@ type Server struct {\n")
+	b.WriteString("+func (s *Server) Shutdown(ctx context.Context) error {\n")
+	for i := range 80 {
+		fmt.Fprintf(&b, "+\ts.step%d()\n", i)
+	}
+	b.WriteString("diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n@@ -1,1 +1,1 @@\n-old\n+new\n")
+	return b.String() + statusSeparator + "M  go.sum\nM  main.go\nM  server.go\n"
+}
+
+func TestFitDiff(t *testing.T) {
+	diff := testDiff()
+	if got, notes := fitDiff(diff, 0); got != diff || notes != nil {
+		t.Fatalf("expected the diff untouched without budget")
+	}
+	for _, test := range []struct {
+		name     string
+		budget   int
+		contains []string
+		notes    []string
+	}{
+		{
+			name:     "generated",
+			budget:   estimateTokens(diff) - 100,
+			contains: []string{"[elided: go.sum | +200 -0]", "+\ts.step79()", "+new"},
+			notes:    []string{"go.sum: generated, only its stat is kept (+200 -0)"},
+		},
+		{
+			name:   "signatures",
+			budget: 200,
+			contains: []string{
+				"@@ -10,3 +10,80 @@ type Server struct {\n+func (s *Server) Shutdown(ctx context.Context) error {\n[... 80 lines elided]",
+				"+new",
+			},
+			notes: []string{
+				"go.sum: generated, only its stat is kept (+200 -0)",
+				"server.go: hunks collapsed to their signatures (+81 -0)",
+			},
+		},
+		{
+			name:     "stat",
+			budget:   70,
+			contains: []string{"[elided: server.go | +81 -0]", "[elided: main.go | +1 -1]"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, notes := fitDiff(diff, test.budget)
+			if estimateTokens(got) > test.budget {
+				t.Errorf("%d tokens over the %d budget", estimateTokens(got), test.budget)
+			}
+			for _, s := range append(test.contains, "M  server.go\n") {
+				if !strings.Contains(got, s) {
+					t.Errorf("expected %q in\n%s", s, got)
+				}
+			}
+			for i, n := range test.notes {
+				if i >= len(notes) || notes[i] != n {
+					t.Errorf("expected note %q in %q", n, notes)
+				}
+			}
+		})
+	}
+	got, notes := fitDiff(diff, 10)
+	if len(got) > 40 || !strings.HasPrefix(notes[len(notes)-1], "the diff was cut") {
+		t.Fatalf("expected a cut diff, got %q %q", got, notes)
+	}
+}
+
+func TestAgentTokenBudget(t *testing.T) {
+	a, err := New(WithTokenBudget(estimateTokens(templateDoc+systemDoc) + elisionReserve + 200))
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	a.(*agent).context.diff = testDiff()
+	got, err := a.UserPrompt()
+	if err != nil {
+		t.Fatalf("user prompt: %s", err)
+	}
+	if !strings.Contains(got, "Parts of the diff were elided") ||
+		!strings.Contains(got, "- go.sum: generated") {
+		t.Fatalf("expected elided parts in prompt\n%s", got)
+	}
+}
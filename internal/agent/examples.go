@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/4sp1/yac/internal/commit/message"
+)
+
+// Examples configures the commits of the repository history shown as
+// few-shot examples of its house style.
+type Examples struct {
+	// Count is the number of examples, 0 disables them.
+	Count int
+	// SameScope keeps the commits sharing the agent scope.
+	SameScope bool
+	// SamePaths keeps the commits touching the changed paths.
+	SamePaths bool
+}
+
+// examplesDepth bounds how far back in history examples are looked for.
+const examplesDepth = 200
+
+type example struct {
+	scope   string
+	message string
+}
+
+// parseExamples keeps the well-formed conventional commit messages among the
+// NUL separated ones, most recent first.
+func parseExamples(log string) []example {
+	var examples []example
+	for _, msg := range strings.Split(log, "\x00") {
+		msg = strings.TrimSpace(msg)
+		m, err := message.Parse(msg)
+		if err != nil || !slices.Contains(message.DefaultRules.Types, m.Type) ||
+			strings.TrimSpace(m.Subject) == "" || len(m.Header) > 72 || !m.Separated {
+			continue
+		}
+		examples = append(examples, example{scope: m.Scope, message: msg})
+	}
+	return examples
+}
+
+// pickExamples returns up to Count example messages, of the agent scope only
+// when SameScope is set.
+func (a agent) pickExamples() []string {
+	var picked []string
+	for _, e := range a.context.examples {
+		if len(picked) >= a.context.examplesCfg.Count {
+			break
+		}
+		if a.context.examplesCfg.SameScope && a.context.scope != "" && e.scope != a.context.scope {
+			continue
+		}
+		picked = append(picked, e.message)
+	}
+	return picked
+}
+
+// WithGitExamples configures the agent to show recent conventional commits of
+// the git history as examples.
+func WithGitExamples(e Examples) Option {
+	return &option{
+		apply: func(ac AgentContext) (AgentContext, error) {
+			if e.Count <= 0 {
+				return ac, nil
+			}
+			args := []string{"log", "--no-merges", "-n", strconv.Itoa(examplesDepth),
+				"--format=%B%x00"}
+			if e.SamePaths {
+				out, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+				if err != nil {
+					return ac, fmt.Errorf("git diff: %w", err)
+				}
+				args = append(append(args, "--"), lines(string(out))...)
+			}
+			out, err := exec.Command("git", args...).Output()
+			if err != nil {
+				return ac, fmt.Errorf("git log: %w", err)
+			}
+			ac.examples = parseExamples(string(out))
+			ac.examplesCfg = e
+			return ac, nil
+		},
+		description: fmt.Sprintf("git examples %d", e.Count),
+	}
+}
+
+// WithJujutsuExamples configures the agent to show recent conventional
+// commits of the jj history as examples.
+func WithJujutsuExamples(e Examples) Option {
+	return &option{
+		apply: func(ac AgentContext) (AgentContext, error) {
+			if e.Count <= 0 {
+				return ac, nil
+			}
+			args := []string{"log", "--no-graph",
+				"-r", fmt.Sprintf("ancestors(@-, %d) ~ merges()", examplesDepth),
+				"-T", `description ++ "\0"`}
+			if e.SamePaths {
+				out, err := exec.Command("jj", "diff", "--name-only").Output()
+				if err != nil {
+					return ac, fmt.Errorf("jj diff: %w", err)
+				}
+				args = append(args, lines(string(out))...)
+			}
+			out, err := exec.Command("jj", args...).Output()
+			if err != nil {
+				return ac, fmt.Errorf("jj log: %w", err)
+			}
+			ac.examples = parseExamples(string(out))
+			ac.examplesCfg = e
+			return ac, nil
+		},
+		description: fmt.Sprintf("jj examples %d", e.Count),
+	}
+}
+
+func lines(s string) []string {
+	var l []string
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			l = append(l, line)
+		}
+	}
+	return l
+}
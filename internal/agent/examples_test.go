@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	log := strings.Join([]string{
+		"feat(api): add pagination\n\nLists were unbounded.\n",
+		"Merge branch 'main'\n",
+		"fix: handle empty diff\n",
+		"wip\n",
+		"docs(api): describe pagination\nno blank line after the header\n",
+		"unknown(api): not a conventional type\n",
+		"refactor(api)!: rename the client\n",
+		"",
+	}, "\x00")
+	examples := parseExamples(log)
+	var scopes []string
+	for _, e := range examples {
+		scopes = append(scopes, e.scope)
+	}
+	if strings.Join(scopes, ",") != "api,,api" {
+		t.Fatalf("unexpected examples %+v", examples)
+	}
+
+	a := agent{context: AgentContext{
+		scope:       "api",
+		examples:    examples,
+		examplesCfg: Examples{Count: 2, SameScope: true},
+	}}
+	picked := a.pickExamples()
+	if len(picked) != 2 || picked[1] != "refactor(api)!: rename the client" {
+		t.Fatalf("unexpected picked examples %q", picked)
+	}
+	a.context.diff = "some diff"
+	got, err := a.UserPrompt()
+	if err != nil {
+		t.Fatalf("user prompt: %s", err)
+	}
+	if !strings.Contains(got, "<example>\nfeat(api): add pagination\n\nLists were unbounded.\n</example>") {
+		t.Fatalf("examples not rendered\n%s", got)
+	}
+}
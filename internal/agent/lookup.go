@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultTemplate is the name of the embedded user prompt template.
+const DefaultTemplate = "user"
+
+// SystemTemplate is the name of the embedded system prompt template, which
+// can be overridden in the search path as well.
+const SystemTemplate = "system"
+
+// TemplateExt is the extension of the template files in the search path.
+const TemplateExt = ".gotmpl"
+
+// TemplateDirs returns the template search path: the repository templates
+// then the user config templates.
+func TemplateDirs(root string) []string {
+	dirs := []string{filepath.Join(root, ".yac", "templates")}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "yac", "templates"))
+	}
+	return dirs
+}
+
+// LookupTemplate returns the named template document from the first
+// directory holding a name.gotmpl file, or the embedded default when name is
+// [DefaultTemplate] or [SystemTemplate] and no directory overrides it.
+func LookupTemplate(name string, dirs ...string) (string, error) {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name+TemplateExt)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", fmt.Errorf("read template: %w", err)
+		}
+		return string(b), nil
+	}
+	switch name {
+	case DefaultTemplate:
+		return templateDoc, nil
+	case SystemTemplate:
+		return systemDoc, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrTemplateNotFound, name)
+}
+
+var ErrTemplateNotFound = errors.New("template not found")
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupTemplate(t *testing.T) {
+	repo, user := t.TempDir(), t.TempDir()
+	for path, doc := range map[string]string{
+		filepath.Join(repo, "house"+TemplateExt):   "repo house",
+		filepath.Join(user, "house"+TemplateExt):   "user house",
+		filepath.Join(user, "terse"+TemplateExt):   "user terse",
+		filepath.Join(user, "ignored.txt"):         "not a template",
+		filepath.Join(user, DefaultTemplate+".md"): "not a template",
+	} {
+		if err := os.WriteFile(path, []byte(doc), 0600); err != nil {
+			t.Fatalf("write %q: %s", path, err)
+		}
+	}
+	for _, test := range []struct {
+		name          string
+		expected      string
+		expectedError error
+	}{
+		{name: "house", expected: "repo house"},
+		{name: "terse", expected: "user terse"},
+		{name: DefaultTemplate, expected: templateDoc},
+		{name: "ignored", expectedError: ErrTemplateNotFound},
+	} {
+		got, err := LookupTemplate(test.name, repo, user)
+		if !errors.Is(err, test.expectedError) {
+			t.Fatalf("%s: expected error %v got %v", test.name, test.expectedError, err)
+		}
+		if got != test.expected {
+			t.Fatalf("%s: expected %q got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestAgentTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "short"+TemplateExt)
+	if err := os.WriteFile(path, []byte("{{.Scope}}: {{.Diff}}"), 0600); err != nil {
+		t.Fatalf("write %q: %s", path, err)
+	}
+	a, err := New(WithTemplate("short", dir))
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	a.(*agent).context.diff = "some diff"
+	a.(*agent).context.scope = "api"
+	got, err := a.UserPrompt()
+	if err != nil {
+		t.Fatalf("user prompt: %s", err)
+	}
+	if got != "api: some diff" {
+		t.Fatalf("unexpected prompt %q", got)
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/4sp1/yac/internal/commit/scope"
 	"github.com/4sp1/yac/internal/commit/wip"
-	"github.com/4sp1/yac/internal/snake"
 
 	"go.uber.org/zap"
 )
@@ -67,8 +66,7 @@ func WithGitDiff() Option {
 				}
 				status = out.String()
 			}
-			ac.diff = fmt.Sprintf("%s\n\n----------------\n\ngit status -s\n\n%s",
-				string(comb), status)
+			ac.diff = string(comb) + statusSeparator + status
 			return ac, nil
 		},
 		description: "git diff",
@@ -103,8 +101,7 @@ func WithJujutsuGitDiff() Option {
 				}
 				status = string(comb)
 			}
-			ac.diff = fmt.Sprintf("%s\n\n----------------\n\ngit status -s\n\n%s",
-				stdout.String(), status)
+			ac.diff = stdout.String() + statusSeparator + status
 			return ac, nil
 		},
 		description: "jj diff --git",
@@ -114,11 +111,7 @@ func WithJujutsuGitDiff() Option {
 func WithScope(sc scope.Scope) Option {
 	return &option{
 		apply: func(ac AgentContext) (AgentContext, error) {
-			if sc == scope.Other {
-				ac.scope = ""
-			} else {
-				ac.scope = snake.Case(sc.String())
-			}
+			ac.scope = sc.Name()
 			return ac, nil
 		},
 		description: fmt.Sprintf("set scope %q", sc),
@@ -168,3 +161,54 @@ func WithNote(note string, kind wip.Context) Option {
 		description: "wip note",
 	}
 }
+
+// WithTokenBudget bounds the prompt to about tokens, the diff is shrunk to fit
+// when it is too large.
+func WithTokenBudget(tokens int) Option {
+	return &option{
+		apply: func(ac AgentContext) (AgentContext, error) {
+			ac.budget = tokens
+			return ac, nil
+		},
+		description: fmt.Sprintf("token budget %d", tokens),
+	}
+}
+
+// WithTemplate configures the agent to fill the named template, looked up in
+// dirs before the embedded ones. The system template is looked up the same
+// way.
+func WithTemplate(name string, dirs ...string) Option {
+	return &option{
+		apply: func(ac AgentContext) (AgentContext, error) {
+			doc, err := LookupTemplate(name, dirs...)
+			if err != nil {
+				return ac, err
+			}
+			ac.template = doc
+			system, err := LookupTemplate(SystemTemplate, dirs...)
+			if err != nil {
+				return ac, err
+			}
+			ac.system = system
+			return ac, nil
+		},
+		description: fmt.Sprintf("template %q", name),
+	}
+}
+
+// WithProfile configures the agent to fill the named built-in profile. The
+// profiles carry their own rules so no system prompt is sent along.
+func WithProfile(name string) Option {
+	return &option{
+		apply: func(ac AgentContext) (AgentContext, error) {
+			doc, err := LookupProfile(name)
+			if err != nil {
+				return ac, err
+			}
+			ac.template = doc
+			ac.system = ""
+			return ac, nil
+		},
+		description: fmt.Sprintf("profile %q", name),
+	}
+}
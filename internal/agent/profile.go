@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+//go:embed profiles/*.gotmpl
+var profileFS embed.FS
+
+// ProfileFull is the base of every built-in profile, the other ones add their
+// section to it.
+const ProfileFull = "full"
+
+// Profiles lists the built-in prompt profiles.
+func Profiles() []string {
+	return []string{ProfileFull, "wip", "examples"}
+}
+
+// LookupProfile returns the built-in profile template document.
+func LookupProfile(name string) (string, error) {
+	base, err := profileFS.ReadFile(path.Join("profiles", ProfileFull+TemplateExt))
+	if err != nil {
+		return "", fmt.Errorf("read profile %q: %w", ProfileFull, err)
+	}
+	if name == ProfileFull {
+		return string(base), nil
+	}
+	extra, err := profileFS.ReadFile(path.Join("profiles", name+TemplateExt))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w %q", ErrProfileNotFound, name)
+		}
+		return "", fmt.Errorf("read profile %q: %w", name, err)
+	}
+	return string(base) + string(extra), nil
+}
+
+var ErrProfileNotFound = errors.New("profile not found")
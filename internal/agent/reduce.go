@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+
+	"go.uber.org/zap"
+)
+
+//go:embed chunk.gotmpl
+var chunkDoc string
+
+//go:generate stringer -type=Split -trimprefix=Split
+type Split int
+
+const (
+	SplitFile Split = iota
+	SplitDir
+	SplitUpperBound // only use in for loop
+)
+
+// ParseSplit parses the lower case name of a [Split].
+func ParseSplit(s string) (Split, error) {
+	for sp := range SplitUpperBound {
+		if strings.ToLower(sp.String()) == s {
+			return sp, nil
+		}
+	}
+	return SplitUpperBound, fmt.Errorf("%w %q", ErrUnknownSplit, s)
+}
+
+// Chunk is a part of the diff summarized on its own.
+type Chunk struct {
+	Name string
+	Diff string
+}
+
+// Summary is the provider answer to a chunk prompt.
+type Summary struct {
+	Name string
+	Text string
+}
+
+// Summarize asks the provider for the summary answering prompt.
+type Summarize func(ctx context.Context, prompt string) (string, error)
+
+// chunks splits the diff per file, or per directory, in order of appearance.
+func (a agent) chunks(split Split) []Chunk {
+	body, _, _ := strings.Cut(a.context.diff, statusSeparator)
+	_, files := parseDiff(body)
+	var (
+		chunks []Chunk
+		index  = map[string]int{}
+	)
+	for _, f := range files {
+		name := f.path
+		if split == SplitDir {
+			name = path.Dir(f.path)
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(chunks)
+			index[name] = i
+			chunks = append(chunks, Chunk{Name: name})
+		}
+		chunks[i].Diff += f.String()
+	}
+	return chunks
+}
+
+// chunkPrompt is the prompt asking for the summary of c, its diff shrunk to
+// the token budget.
+func (a agent) chunkPrompt(c Chunk) (string, error) {
+	t, err := template.New("chunk").Parse(chunkDoc)
+	if err != nil {
+		return "", fmt.Errorf("parse template: %w", err)
+	}
+	var budget int
+	if a.context.budget > 0 {
+		budget = max(a.context.budget-estimateTokens(chunkDoc)-elisionReserve, 1)
+	}
+	diff, elided := fitDiff(c.Diff, budget)
+	var b bytes.Buffer
+	if err := t.Execute(&b, struct {
+		Name   string
+		Diff   string
+		Elided []string
+	}{c.Name, diff, elided}); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// MapReduce summarizes the chunks of the diff with at most jobs concurrent
+// summarize calls. The returned agent prompts with the summaries and the vcs
+// status in place of the diff.
+func (a agent) MapReduce(ctx context.Context, split Split, jobs int,
+	summarize Summarize) (Agent, error) {
+	chunks := a.chunks(split)
+	if len(chunks) == 0 {
+		return nil, ErrNoChunks
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, max(jobs, 1))
+		summaries = make([]Summary, len(chunks))
+		errs      = make([]error, len(chunks))
+	)
+	for i, c := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			prompt, err := a.chunkPrompt(c)
+			if err != nil {
+				errs[i] = fmt.Errorf("chunk %q: %w", c.Name, err)
+				cancel()
+				return
+			}
+			text, err := summarize(ctx, prompt)
+			if err != nil {
+				errs[i] = fmt.Errorf("chunk %q: %w", c.Name, err)
+				cancel()
+				return
+			}
+			summaries[i] = Summary{Name: c.Name, Text: strings.TrimSpace(text)}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if a.context.logger != nil {
+		a.context.logger.Debug("diff summarized",
+			zap.Stringer("split", split),
+			zap.Int("chunks", len(chunks)))
+	}
+	a.context.summaries = summaries
+	return &a, nil
+}
+
+var (
+	ErrUnknownSplit = errors.New("unknown split")
+	ErrNoChunks     = errors.New("no file in diff")
+)
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const reduceDiff = "diff --git a/cmd/root.go b/cmd/root.go\n@@ -1 +1 @@\n-a\n+b\n" +
+	"diff --git a/cmd/lint.go b/cmd/lint.go\n@@ -1 +1 @@\n-c\n+d\n" +
+	"diff --git a/main.go b/main.go\n@@ -1 +1 @@\n-e\n+f\n" +
+	statusSeparator + "M  cmd/lint.go\nM  cmd/root.go\nM  main.go\n"
+
+func TestChunks(t *testing.T) {
+	a := agent{context: AgentContext{diff: reduceDiff}}
+	for split, expected := range map[Split][]string{
+		SplitFile: {"cmd/root.go", "cmd/lint.go", "main.go"},
+		SplitDir:  {"cmd", "."},
+	} {
+		chunks := a.chunks(split)
+		if len(chunks) != len(expected) {
+			t.Fatalf("%s: expected %d chunks got %d", split, len(expected), len(chunks))
+		}
+		for i, c := range chunks {
+			if c.Name != expected[i] || !strings.HasPrefix(c.Diff, "diff --git") {
+				t.Errorf("%s: unexpected chunk %+v", split, c)
+			}
+		}
+	}
+}
+
+func TestMapReduce(t *testing.T) {
+	a := agent{context: AgentContext{diff: reduceDiff}}
+	var calls atomic.Int32
+	reduced, err := a.MapReduce(context.Background(), SplitDir, 2,
+		func(ctx context.Context, prompt string) (string, error) {
+			calls.Add(1)
+			if strings.Contains(prompt, `<diff path="cmd">`) {
+				return "cmd summary\n", nil
+			}
+			return "main summary", nil
+		})
+	if err != nil {
+		t.Fatalf("map reduce: %s", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 summarize calls got %d", calls.Load())
+	}
+	got, err := reduced.UserPrompt()
+	if err != nil {
+		t.Fatalf("user prompt: %s", err)
+	}
+	for _, s := range []string{
+		"<summary path=\"cmd\">\ncmd summary\n</summary>",
+		"<summary path=\".\">\nmain summary\n</summary>",
+		"git status -s\n\nM  cmd/lint.go",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("expected %q in\n%s", s, got)
+		}
+	}
+	if strings.Contains(got, "+b\n") {
+		t.Errorf("expected the diff to be left out\n%s", got)
+	}
+
+	errSummarize := errors.New("summarize")
+	_, err = a.MapReduce(context.Background(), SplitFile, 1,
+		func(ctx context.Context, prompt string) (string, error) {
+			return "", errSummarize
+		})
+	if !errors.Is(err, errSummarize) {
+		t.Fatalf("expected %q got %v", errSummarize, err)
+	}
+	if _, err := (agent{}).MapReduce(context.Background(), SplitFile, 1, nil); !errors.Is(err, ErrNoChunks) {
+		t.Fatalf("expected %q got %v", ErrNoChunks, err)
+	}
+}
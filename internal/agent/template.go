@@ -11,14 +11,26 @@ import (
 //go:embed user.gotmpl
 var templateDoc string
 
+//go:embed system.gotmpl
+var systemDoc string
+
 type templateFiller struct {
-	GitLog         string
-	Scope          string
-	Diff           string
+	GitLog string
+	Scope  string
+	Diff   string
+	// Elided lists the parts of the diff left out to fit the token budget.
+	Elided []string
+	// Summaries stand for the diff parts when it was map-reduced.
+	Summaries []Summary
+	// Examples are commit messages of the repository showing its style.
+	Examples []string
+	// Breaking lists the possible breaking changes found in the diff.
+	Breaking       []string
 	IdealFuture    []idealSection
 	IdealSeparator string
 
 	hasSeparator bool
+	doc          string
 }
 
 type idealSection struct {
@@ -28,13 +40,25 @@ type idealSection struct {
 
 type fillerOpt func(templateFiller) templateFiller
 
+// withDoc replaces the embedded template document.
+func withDoc(doc string) fillerOpt {
+	return func(tf templateFiller) templateFiller {
+		tf.doc = doc
+		return tf
+	}
+}
+
 func (tf templateFiller) Fill(w io.Writer, opts ...fillerOpt) error {
 	for _, opt := range opts {
 		tf = opt(tf)
 	}
-	t, err := template.New("user").Parse(templateDoc)
+	doc := templateDoc
+	if tf.doc != "" {
+		doc = tf.doc
+	}
+	t, err := template.New("user").Parse(doc)
 	if err != nil {
-		return fmt.Errorf("parse embedded template: %w", err)
+		return fmt.Errorf("parse template: %w", err)
 	}
 	var out bytes.Buffer
 	if err := t.Execute(io.MultiWriter(&out, w), tf); err != nil {
@@ -2,11 +2,15 @@ package agent
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"text/template"
+	"text/template/parse"
 
 	"github.com/4sp1/yac/internal/commit/wip"
 )
@@ -100,3 +104,137 @@ func TestAgentFill(t *testing.T) {
 		})
 	}
 }
+
+// fieldNames collects the field names referenced anywhere in the template.
+func fieldNames(node parse.Node, names map[string]struct{}) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			fieldNames(c, names)
+		}
+	case *parse.ActionNode:
+		fieldNames(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			fieldNames(c, names)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			fieldNames(arg, names)
+		}
+	case *parse.FieldNode:
+		for _, ident := range n.Ident {
+			names[ident] = struct{}{}
+		}
+	case *parse.IfNode:
+		fieldNames(&n.BranchNode, names)
+	case *parse.RangeNode:
+		fieldNames(&n.BranchNode, names)
+	case *parse.WithNode:
+		fieldNames(&n.BranchNode, names)
+	case *parse.BranchNode:
+		fieldNames(n.Pipe, names)
+		fieldNames(n.List, names)
+		fieldNames(n.ElseList, names)
+	}
+}
+
+func TestTemplateConsumesFiller(t *testing.T) {
+	docs := map[string]string{DefaultTemplate: templateDoc}
+	for _, name := range Profiles() {
+		doc, err := LookupProfile(name)
+		if err != nil {
+			t.Fatalf("lookup profile %q: %s", name, err)
+		}
+		docs[name] = doc
+	}
+	for name, doc := range docs {
+		tmpl, err := template.New("user").Parse(doc)
+		if err != nil {
+			t.Fatalf("parse %q template: %s", name, err)
+		}
+		names := map[string]struct{}{}
+		for _, tree := range tmpl.Templates() {
+			fieldNames(tree.Tree.Root, names)
+		}
+		for _, typ := range []reflect.Type{
+			reflect.TypeOf(templateFiller{}),
+			reflect.TypeOf(idealSection{}),
+			reflect.TypeOf(Summary{}),
+		} {
+			for i := range typ.NumField() {
+				f := typ.Field(i)
+				if !f.IsExported() {
+					continue
+				}
+				if _, ok := names[f.Name]; !ok {
+					t.Errorf("%s.%s is not consumed by the %q template", typ.Name(), f.Name, name)
+				}
+			}
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	for _, name := range Profiles() {
+		a, err := New(WithProfile(name))
+		if err != nil {
+			t.Fatalf("new agent with profile %q: %s", name, err)
+		}
+		a.(*agent).context.diff = "some diff"
+		got, err := a.UserPrompt()
+		if err != nil {
+			t.Fatalf("profile %q: user prompt: %s", name, err)
+		}
+		if !strings.Contains(got, "<diff>\nsome diff\n</diff>") {
+			t.Errorf("profile %q: diff not rendered", name)
+		}
+		extra := map[string]string{
+			"wip":      "3. **`wip_context`** (optional)",
+			"examples": "### **Example 1: With Git Log Context**",
+		}[name]
+		if !strings.Contains(got, extra) {
+			t.Errorf("profile %q: extra section not rendered", name)
+		}
+	}
+	if _, err := New(WithProfile("unknown")); !errors.Is(err, ErrProfileNotFound) {
+		t.Fatalf("expected %q got %v", ErrProfileNotFound, err)
+	}
+}
+
+func TestSystemPrompt(t *testing.T) {
+	a, err := New()
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	a.(*agent).context.diff = "some diff"
+	system, err := a.SystemPrompt()
+	if err != nil {
+		t.Fatalf("system prompt: %s", err)
+	}
+	user, err := a.UserPrompt()
+	if err != nil {
+		t.Fatalf("user prompt: %s", err)
+	}
+	const rule = "## CRITICAL OUTPUT REQUIREMENT"
+	if !strings.Contains(system, rule) || strings.Contains(user, rule) {
+		t.Errorf("rules are expected in the system prompt only")
+	}
+	if strings.Contains(system, "some diff") {
+		t.Errorf("diff is expected in the user prompt only")
+	}
+
+	a, err = New(WithProfile(ProfileFull))
+	if err != nil {
+		t.Fatalf("new agent: %s", err)
+	}
+	if system, err := a.SystemPrompt(); err != nil || system != "" {
+		t.Fatalf("profiles are expected without system prompt, got %q %v", system, err)
+	}
+}
@@ -12,6 +12,18 @@ import (
 type Flags interface {
 	FlagsLogs() []string
 	FlagsWip() map[wip.Context][]string
+	FlagsProvider() Provider
+	FlagsFallback() []Provider
+	// FlagsScope is the name of the commit scope, empty for none.
+	FlagsScope() string
+}
+
+// Provider selects the LLM provider answering the commit prompt. Empty fields
+// keep the command line (or provider default) values.
+type Provider struct {
+	Name    string `yaml:"name" json:"name"`
+	Model   string `yaml:"model" json:"model"`
+	BaseURL string `yaml:"base_url" json:"base_url"`
 }
 
 var _ Flags = &configJSON{}
@@ -29,8 +41,11 @@ const (
 type Config = *config
 
 type config struct {
-	Wip  map[wip.Context][]string
-	Logs []string
+	Wip      map[wip.Context][]string
+	Logs     []string
+	Provider Provider
+	Fallback []Provider
+	Scope    string
 }
 
 var _ yaml.Unmarshaler = &config{}
@@ -44,8 +59,11 @@ func (f *config) UnmarshalYAML(n *yaml.Node) error {
 		return fmt.Errorf("unmarshal flag json: %w", err)
 	}
 	*f = config{
-		Wip:  v.Wip.M,
-		Logs: v.Logs,
+		Wip:      v.Wip.M,
+		Logs:     v.Logs,
+		Provider: v.Provider,
+		Fallback: v.Fallback,
+		Scope:    v.Scope,
 	}
 	return nil
 }
@@ -58,8 +76,11 @@ func (f *config) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("unmarshal flag json: %w", err)
 	}
 	*f = config{
-		Wip:  v.Wip.M,
-		Logs: v.Logs,
+		Wip:      v.Wip.M,
+		Logs:     v.Logs,
+		Provider: v.Provider,
+		Fallback: v.Fallback,
+		Scope:    v.Scope,
 	}
 	return nil
 }
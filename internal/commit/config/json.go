@@ -9,15 +9,19 @@ import (
 
 func NewJson(s scope.Scope) ConfigJSON {
 	return &configJSON{
-		Wip:  wip.NewWrap(),
-		Logs: []string{},
+		Wip:   wip.NewWrap(),
+		Logs:  []string{},
+		Scope: s.Name(),
 	}
 }
 
 func FromJSON(c Config) ConfigJSON {
 	return &configJSON{
-		Wip:  wip.Wrap{M: c.Wip},
-		Logs: c.Logs,
+		Wip:      wip.Wrap{M: c.Wip},
+		Logs:     c.Logs,
+		Provider: c.Provider,
+		Fallback: c.Fallback,
+		Scope:    c.Scope,
 	}
 }
 
@@ -25,10 +29,16 @@ type ConfigJSON = *configJSON
 
 func (f *configJSON) FlagsLogs() []string                { return f.Logs }
 func (f *configJSON) FlagsWip() map[wip.Context][]string { return f.Wip.M }
+func (f *configJSON) FlagsProvider() Provider            { return f.Provider }
+func (f *configJSON) FlagsFallback() []Provider          { return f.Fallback }
+func (f *configJSON) FlagsScope() string                 { return f.Scope }
 
 type configJSON struct {
-	Wip  wip.Wrap `yaml:"wip_context"`
-	Logs []string `yaml:"logs"`
+	Wip      wip.Wrap   `yaml:"wip_context"`
+	Logs     []string   `yaml:"logs"`
+	Provider Provider   `yaml:"provider"`
+	Fallback []Provider `yaml:"fallback"`
+	Scope    string     `yaml:"scope"`
 }
 
 var _ json.Marshaler = &configJSON{}
@@ -39,11 +49,17 @@ func (f *configJSON) MarshalJSON() ([]byte, error) {
 		m[section.String()] = notes
 	}
 	v := struct {
-		Wip  map[string][]string
-		Logs []string
+		Wip      map[string][]string
+		Logs     []string
+		Provider Provider
+		Fallback []Provider
+		Scope    string `json:",omitempty"`
 	}{
-		Wip:  m,
-		Logs: f.Logs,
+		Wip:      m,
+		Logs:     f.Logs,
+		Provider: f.Provider,
+		Fallback: f.Fallback,
+		Scope:    f.Scope,
 	}
 	return json.Marshal(v)
 }
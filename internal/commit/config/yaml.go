@@ -9,15 +9,19 @@ import (
 
 func NewYaml(s scope.Scope) ConfigYAML {
 	return &configYAML{
-		Wip:  wip.NewWrap(),
-		Logs: []string{},
+		Wip:   wip.NewWrap(),
+		Logs:  []string{},
+		Scope: s.Name(),
 	}
 }
 
 func FromYAML(c Config) ConfigYAML {
 	return &configYAML{
-		Wip:  wip.Wrap{M: c.Wip},
-		Logs: c.Logs,
+		Wip:      wip.Wrap{M: c.Wip},
+		Logs:     c.Logs,
+		Provider: c.Provider,
+		Fallback: c.Fallback,
+		Scope:    c.Scope,
 	}
 }
 
@@ -25,10 +29,16 @@ type ConfigYAML = *configYAML
 
 func (f *configYAML) FlagsLogs() []string                { return f.Logs }
 func (f *configYAML) FlagsWip() map[wip.Context][]string { return f.Wip.M }
+func (f *configYAML) FlagsProvider() Provider            { return f.Provider }
+func (f *configYAML) FlagsFallback() []Provider          { return f.Fallback }
+func (f *configYAML) FlagsScope() string                 { return f.Scope }
 
 type configYAML struct {
-	Wip  wip.Wrap `yaml:"wip_context"`
-	Logs []string `yaml:"logs"`
+	Wip      wip.Wrap   `yaml:"wip_context"`
+	Logs     []string   `yaml:"logs"`
+	Provider Provider   `yaml:"provider"`
+	Fallback []Provider `yaml:"fallback"`
+	Scope    string     `yaml:"scope"`
 }
 
 var _ yaml.Marshaler = &configYAML{}
@@ -39,11 +49,17 @@ func (f *configYAML) MarshalYAML() (interface{}, error) {
 		m[section.String()] = notes
 	}
 	v := struct {
-		Wip  map[string][]string `yaml:"wip_context"`
-		Logs []string            `yaml:"logs"`
+		Wip      map[string][]string `yaml:"wip_context"`
+		Logs     []string            `yaml:"logs"`
+		Provider Provider            `yaml:"provider"`
+		Fallback []Provider          `yaml:"fallback"`
+		Scope    string              `yaml:"scope,omitempty"`
 	}{
-		Wip:  m,
-		Logs: f.Logs,
+		Wip:      m,
+		Logs:     f.Logs,
+		Provider: f.Provider,
+		Fallback: f.Fallback,
+		Scope:    f.Scope,
 	}
 	return v, nil
 }
@@ -0,0 +1,123 @@
+package message
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// trailers are the canonical spelling of the well known footer tokens.
+var trailers = []string{
+	BreakingChange, "Refs", "Fixes", "Closes", "Co-authored-by", "Signed-off-by",
+	"Reviewed-by",
+}
+
+var bulletPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+
+// Format rewraps the body paragraphs of a commit message to width columns,
+// writes the footers as git trailers ("Token: value") and strips trailing
+// whitespace. Bullet lists are wrapped item by item, code blocks and words
+// longer than width, like URLs, are kept as they are. The header is left
+// alone, even when it is not conventional, the body and footers being
+// formatted nonetheless; an empty message is returned unchanged.
+func Format(s string, width int) string {
+	m, err := Parse(s)
+	if errors.Is(err, ErrEmpty) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteString(m.FormatHeader())
+	if m.Body != "" {
+		b.WriteString("\n\n" + wrap(m.Body, width))
+	}
+	if len(m.Footers) > 0 {
+		b.WriteString("\n")
+		for _, f := range m.Footers {
+			b.WriteString("\n" + f.trailer())
+		}
+	}
+	return b.String()
+}
+
+// trailer renders the footer in the git trailer format, its token spelled the
+// canonical way.
+func (f Footer) trailer() string {
+	token := f.Token
+	if token == BreakingChangeAlias {
+		token = BreakingChange
+	}
+	for _, t := range trailers {
+		if strings.EqualFold(token, t) {
+			token = t
+		}
+	}
+	lines := strings.Split(f.Value, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return token + ": " + strings.Join(lines, "\n")
+}
+
+// wrap rewraps the paragraphs and list items of body to width columns.
+func wrap(body string, width int) string {
+	var (
+		out    []string
+		words  []string
+		prefix string // first line prefix of the pending paragraph
+		indent string // next lines prefix of the pending paragraph
+		fenced bool
+	)
+	flush := func() {
+		if len(words) > 0 {
+			out = append(out, fill(words, prefix, indent, width)...)
+		}
+		words, prefix, indent = nil, "", ""
+	}
+	for _, l := range strings.Split(body, "\n") {
+		l = strings.TrimRight(l, " \t")
+		trimmed := strings.TrimSpace(l)
+		switch {
+		case fenced || strings.HasPrefix(trimmed, "```"):
+			flush()
+			out = append(out, l)
+			if strings.HasPrefix(trimmed, "```") {
+				fenced = !fenced
+			}
+		case trimmed == "":
+			flush()
+			out = append(out, "")
+		case bulletPattern.MatchString(l):
+			flush()
+			marker := bulletPattern.FindString(l)
+			prefix = strings.TrimRight(marker, " \t") + " "
+			indent = strings.Repeat(" ", utf8.RuneCountInString(prefix))
+			words = strings.Fields(l[len(marker):])
+		case (len(words) == 0 || indent == "") &&
+			(strings.HasPrefix(l, "    ") || strings.HasPrefix(l, "\t")):
+			// indented code block
+			flush()
+			out = append(out, l)
+		default:
+			words = append(words, strings.Fields(l)...)
+		}
+	}
+	flush()
+	return strings.Join(out, "\n")
+}
+
+// fill lays words out on lines of at most width columns, a longer word having
+// its own line.
+func fill(words []string, prefix, indent string, width int) []string {
+	var lines []string
+	line := prefix + words[0]
+	for _, w := range words[1:] {
+		if width > 0 && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(w) > width {
+			lines = append(lines, line)
+			line = indent + w
+			continue
+		}
+		line += " " + w
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,89 @@
+package message
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "header only",
+			input:    "feat: Add pagination  \n\n",
+			expected: "feat: Add pagination",
+		},
+		{
+			name: "paragraphs",
+			input: "feat(api): Add pagination\n" +
+				"The users endpoint previously fetched all records without pagination, causing slow responses. \n" +
+				"Now it pages.\n\n\n" +
+				"See https://example.com/a/very/long/url/that/goes/well/beyond/the/seventy/two/columns for details.",
+			expected: "feat(api): Add pagination\n\n" +
+				"The users endpoint previously fetched all records without pagination,\n" +
+				"causing slow responses. Now it pages.\n\n\n" +
+				"See\n" +
+				"https://example.com/a/very/long/url/that/goes/well/beyond/the/seventy/two/columns\n" +
+				"for details.",
+		},
+		{
+			name: "lists and code",
+			input: "fix: Handle empty diff\n\n" +
+				"- the prompt template crashed on an empty diff because the range over the files had no guard\n" +
+				"  against it\n" +
+				"* short item\n" +
+				"10. numbered item\n\n" +
+				"```\n" +
+				"a very long line of code which must be kept as it is whatever its length is\n" +
+				"```\n\n" +
+				"    indented code block line which must be kept as it is whatever its length",
+			expected: "fix: Handle empty diff\n\n" +
+				"- the prompt template crashed on an empty diff because the range over\n" +
+				"  the files had no guard against it\n" +
+				"* short item\n" +
+				"10. numbered item\n\n" +
+				"```\n" +
+				"a very long line of code which must be kept as it is whatever its length is\n" +
+				"```\n\n" +
+				"    indented code block line which must be kept as it is whatever its length",
+		},
+		{
+			name: "trailers",
+			input: "feat!: Rename client\n\nBody.\n\n" +
+				"BREAKING-CHANGE: the client is renamed \n  and its options too\n" +
+				"refs #12\nco-authored-by: Someone <someone@example.com>\nX-Custom: kept",
+			expected: "feat!: Rename client\n\nBody.\n\n" +
+				"BREAKING CHANGE: the client is renamed\n  and its options too\n" +
+				"Refs: #12\nCo-authored-by: Someone <someone@example.com>\nX-Custom: kept",
+		},
+		{
+			name: "prose note",
+			input: "feat: Add client\n\nBody.\n\n" +
+				"Note: the old client keeps working\nuntil v2",
+			expected: "feat: Add client\n\nBody.\n\n" +
+				"Note: the old client keeps working until v2",
+		},
+		{
+			name:     "not conventional",
+			input:    "Update things \nwithout a blank line  \n",
+			expected: "Update things\n\nwithout a blank line",
+		},
+		{
+			name:     "empty",
+			input:    " \n",
+			expected: " \n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := Format(test.input, 72)
+			if got != test.expected {
+				t.Fatalf("expected\n%s\ngot\n%s", test.expected, got)
+			}
+			if again := Format(got, 72); again != got && test.name != "empty" {
+				t.Fatalf("format is not idempotent\n%s\ngot\n%s", got, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules configures the linter, the zero value only checks the structure of
+// the message.
+type Rules struct {
+	// Types lists the allowed types, empty for any.
+	Types []string
+	// Scopes lists the allowed scopes, empty for any.
+	Scopes         []string
+	RequireScope   bool
+	MaxHeader      int // 0 for no limit
+	MaxBodyLine    int // 0 for no limit, lines holding a URL are exempted
+	CapitalSubject bool
+	// RequireBreaking asks for both the ! marker and a BREAKING CHANGE
+	// footer, when the change is known to break its users.
+	RequireBreaking bool
+}
+
+// DefaultRules are the rules the commit prompt asks the model to follow.
+var DefaultRules = Rules{
+	Types: []string{
+		"feat", "fix", "perf", "refactor", "style", "test", "docs", "build",
+		"ci", "chore", "revert",
+	},
+	MaxHeader:      50,
+	MaxBodyLine:    72,
+	CapitalSubject: true,
+}
+
+// Issue is a broken rule. Line counts from 1, 0 for the whole message.
+type Issue struct {
+	Rule    string
+	Line    int
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.Rule, i.Message)
+	}
+	return fmt.Sprintf("%d: %s: %s", i.Line, i.Rule, i.Message)
+}
+
+// Lint parses s and checks it against the rules.
+func (r Rules) Lint(s string) (Message, []Issue) {
+	m, err := Parse(s)
+	switch {
+	case errors.Is(err, ErrEmpty):
+		return m, []Issue{{Rule: "empty", Message: "the message is empty"}}
+	case errors.Is(err, ErrHeader):
+		issue := Issue{
+			Rule:    "header-format",
+			Line:    1,
+			Message: fmt.Sprintf("%q is not <type>(<scope>): <subject>", m.Header),
+		}
+		switch {
+		case strings.HasPrefix(m.Header, "```"):
+			issue.Rule, issue.Message = "code-fence", "the message is wrapped in a code fence"
+		case strings.HasSuffix(m.Header, ":"):
+			issue.Rule = "preamble"
+			issue.Message = fmt.Sprintf("%q introduces the message instead of being its header", m.Header)
+		}
+		return m, append([]Issue{issue}, r.checkBody(m)...)
+	}
+	return m, r.Check(m)
+}
+
+// Check checks a parsed message against the rules.
+func (r Rules) Check(m Message) []Issue {
+	var issues []Issue
+	add := func(rule string, line int, format string, args ...any) {
+		issues = append(issues, Issue{Rule: rule, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+	if len(r.Types) > 0 && !slices.Contains(r.Types, m.Type) {
+		add("type-enum", 1, "type %q is not one of %s", m.Type, strings.Join(r.Types, ", "))
+	}
+	if m.Scope == "" && r.RequireScope {
+		add("scope-required", 1, "the scope is missing")
+	}
+	if m.Scope != "" && len(r.Scopes) > 0 && !slices.Contains(r.Scopes, m.Scope) {
+		add("scope-enum", 1, "scope %q is not one of %s", m.Scope, strings.Join(r.Scopes, ", "))
+	}
+	subject := strings.TrimSpace(m.Subject)
+	if subject == "" {
+		add("subject-empty", 1, "the subject is empty")
+	} else {
+		if strings.HasSuffix(subject, ".") {
+			add("subject-full-stop", 1, "the subject ends with a period")
+		}
+		if first, _ := utf8.DecodeRuneInString(subject); r.CapitalSubject && unicode.IsLower(first) {
+			add("subject-case", 1, "the subject does not start with a capital letter")
+		}
+	}
+	if n := utf8.RuneCountInString(m.Header); r.MaxHeader > 0 && n > r.MaxHeader {
+		add("header-max-length", 1, "the header is %d characters long, more than %d", n, r.MaxHeader)
+	}
+	if r.RequireBreaking && !m.Bang {
+		add("breaking-bang", 1, "the header has no ! marking the breaking change")
+	}
+	if r.RequireBreaking && !slices.ContainsFunc(m.Footers, Footer.Breaking) {
+		add("breaking-footer", 0, "no %s footer describes the breaking change", BreakingChange)
+	}
+	return append(issues, r.checkBody(m)...)
+}
+
+func (r Rules) checkBody(m Message) []Issue {
+	var issues []Issue
+	if !m.Separated {
+		issues = append(issues, Issue{
+			Rule:    "body-leading-blank",
+			Line:    2,
+			Message: "the header is not followed by a blank line",
+		})
+	}
+	if r.MaxBodyLine <= 0 || m.Body == "" {
+		return issues
+	}
+	// urls and code, fenced or indented, cannot be wrapped
+	var fenced bool
+	for i, l := range strings.Split(m.Body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(l), "```") {
+			fenced = !fenced
+			continue
+		}
+		n := utf8.RuneCountInString(l)
+		if n <= r.MaxBodyLine || fenced || strings.Contains(l, "://") ||
+			strings.HasPrefix(l, "    ") || strings.HasPrefix(l, "\t") {
+			continue
+		}
+		line := i + 3
+		if !m.Separated {
+			line = i + 2
+		}
+		issues = append(issues, Issue{
+			Rule:    "body-max-line-length",
+			Line:    line,
+			Message: fmt.Sprintf("the line is %d characters long, more than %d", n, r.MaxBodyLine),
+		})
+	}
+	return issues
+}
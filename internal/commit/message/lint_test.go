@@ -0,0 +1,108 @@
+package message
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		rules    Rules
+		input    string
+		expected []string
+	}{
+		{
+			name:  "valid",
+			rules: DefaultRules,
+			input: "feat(api): Add pagination\n\nLists were unbounded, see https://example.com/a/very/long/url/that/goes/beyond/the/limit\n",
+		},
+		{
+			name:  "header",
+			rules: DefaultRules,
+			input: "feature(api): add a paginated listing to every endpoint.",
+			expected: []string{
+				`1: type-enum: type "feature" is not one of feat, fix, perf, refactor, style, test, docs, build, ci, chore, revert`,
+				"1: subject-full-stop: the subject ends with a period",
+				"1: subject-case: the subject does not start with a capital letter",
+				"1: header-max-length: the header is 56 characters long, more than 50",
+			},
+		},
+		{
+			name:  "scope",
+			rules: Rules{RequireScope: true, Scopes: []string{"api"}},
+			input: "fix: Handle empty diff",
+			expected: []string{
+				"1: scope-required: the scope is missing",
+			},
+		},
+		{
+			name:  "body",
+			rules: DefaultRules,
+			input: "Add pagination\nLists were unbounded " + strings.Repeat("and unbounded ", 5),
+			expected: []string{
+				`1: header-format: "Add pagination" is not <type>(<scope>): <subject>`,
+				"2: body-leading-blank: the header is not followed by a blank line",
+				"2: body-max-line-length: the line is 90 characters long, more than 72",
+			},
+		},
+		{
+			name:  "fenced code",
+			rules: DefaultRules,
+			input: "feat(api): Add pagination\n\nList a page:\n\n```\n" +
+				"client.List(ctx, api.Page{Size: 100, Token: next}, api.WithRetries(3), api.WithTimeout(time.Minute))\n```\n",
+		},
+		{
+			name:  "indented code",
+			rules: DefaultRules,
+			input: "feat(api): Add pagination\n\nList a page:\n\n" +
+				"    client.List(ctx, api.Page{Size: 100, Token: next}, api.WithRetries(3), api.WithTimeout(time.Minute))\n" +
+				"\tclient.List(ctx, api.Page{Size: 100, Token: next}, api.WithRetries(3), api.WithTimeout(time.Minute))\n",
+		},
+		{
+			name:  "preamble",
+			input: "Here's the commit message:\n\nfeat: Add pagination",
+			expected: []string{
+				`1: preamble: "Here's the commit message:" introduces the message instead of being its header`,
+			},
+		},
+		{
+			name:  "code fence",
+			input: "```\nfeat: Add pagination\n```",
+			expected: []string{
+				"1: code-fence: the message is wrapped in a code fence",
+				"2: body-leading-blank: the header is not followed by a blank line",
+			},
+		},
+		{
+			name:  "breaking",
+			rules: Rules{RequireBreaking: true},
+			input: "feat: Rename client\n\nRefs #12",
+			expected: []string{
+				"1: breaking-bang: the header has no ! marking the breaking change",
+				"breaking-footer: no BREAKING CHANGE footer describes the breaking change",
+			},
+		},
+		{
+			name:  "breaking described",
+			rules: Rules{RequireBreaking: true},
+			input: "feat!: Rename client\n\nBREAKING-CHANGE: the client is renamed",
+		},
+		{
+			name:     "empty",
+			input:    "\n",
+			expected: []string{"empty: the message is empty"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, issues := test.rules.Lint(test.input)
+			got := make([]string, len(issues))
+			for i, issue := range issues {
+				got[i] = issue.String()
+			}
+			if strings.Join(got, "\n") != strings.Join(test.expected, "\n") {
+				t.Fatalf("expected\n%s\ngot\n%s", strings.Join(test.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Package message parses commit messages following the Conventional Commits
+// specification and lints them against configurable rules.
+package message
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Message is a parsed commit message:
+//
+//	<type>(<scope>)!: <subject>
+//
+//	<body>
+//
+//	<footers>
+type Message struct {
+	Header  string
+	Type    string
+	Scope   string
+	Subject string
+	// Breaking is set by a ! in the header or a BREAKING CHANGE footer.
+	Breaking bool
+	// Bang is the ! marker of the header.
+	Bang bool
+	// Separated tells whether a blank line follows the header, as required
+	// when there is a body or footers.
+	Separated bool
+	Body      string
+	Footers   []Footer
+}
+
+// Footer is a git trailer like footer: "Token: value" or "Token #value", in
+// which case Value keeps the # prefix.
+type Footer struct {
+	Token string
+	Value string
+}
+
+const (
+	BreakingChange = "BREAKING CHANGE"
+	// BreakingChangeAlias is the hyphenated synonym of [BreakingChange].
+	BreakingChangeAlias = "BREAKING-CHANGE"
+)
+
+var (
+	headerPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()\r\n]*)\))?(!)?: (.*)$`)
+	footerPattern = regexp.MustCompile(`^(` + BreakingChange + `|[\w-]+)(: | #)(.*)$`)
+)
+
+// Parse parses a commit message. When the header does not follow the
+// Conventional Commits format, the message is returned along with
+// [ErrHeader], its body and footers parsed nonetheless.
+func Parse(s string) (Message, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	if s == "" {
+		return Message{}, ErrEmpty
+	}
+	header, rest, _ := strings.Cut(s, "\n")
+	m := Message{Header: strings.TrimRight(header, " \t")}
+	m.Separated = rest == "" || strings.HasPrefix(rest, "\n")
+	m.Body, m.Footers = parseFooters(strings.Trim(rest, "\n"))
+	for _, f := range m.Footers {
+		if f.Breaking() {
+			m.Breaking = true
+		}
+	}
+
+	match := headerPattern.FindStringSubmatch(m.Header)
+	if match == nil {
+		return m, fmt.Errorf("%w %q", ErrHeader, m.Header)
+	}
+	m.Type, m.Scope, m.Bang, m.Subject = match[1], match[2], match[3] == "!", match[4]
+	m.Breaking = m.Breaking || m.Bang
+	return m, nil
+}
+
+// parseFooters splits the footers from the body. Like git interpret-trailers,
+// the last paragraph holds footers when each of its lines is a footer or an
+// indented continuation of the previous one.
+func parseFooters(s string) (string, []Footer) {
+	var body, last string
+	if i := strings.LastIndex(s, "\n\n"); i >= 0 {
+		body, last = s[:i], s[i+2:]
+	} else {
+		last = s
+	}
+	lines := strings.Split(last, "\n")
+	if !footerPattern.MatchString(lines[0]) {
+		return s, nil
+	}
+	for _, l := range lines[1:] {
+		if !footerPattern.MatchString(l) && !strings.HasPrefix(l, " ") && !strings.HasPrefix(l, "\t") {
+			return s, nil
+		}
+	}
+	var footers []Footer
+	for _, l := range lines {
+		if match := footerPattern.FindStringSubmatch(l); match != nil {
+			value := match[3]
+			if match[2] == " #" {
+				value = "#" + value
+			}
+			footers = append(footers, Footer{Token: match[1], Value: value})
+			continue
+		}
+		// a footer value may span several lines
+		footers[len(footers)-1].Value += "\n" + l
+	}
+	return strings.TrimRight(body, "\n"), footers
+}
+
+// Breaking reports a breaking change footer.
+func (f Footer) Breaking() bool {
+	return f.Token == BreakingChange || f.Token == BreakingChangeAlias
+}
+
+func (f Footer) String() string {
+	if strings.HasPrefix(f.Value, "#") {
+		return f.Token + " " + f.Value
+	}
+	return f.Token + ": " + f.Value
+}
+
+// FormatHeader builds the header from its parts, it is the parsed header when
+// the message has no type.
+func (m Message) FormatHeader() string {
+	if m.Type == "" {
+		return m.Header
+	}
+	var b strings.Builder
+	b.WriteString(m.Type)
+	if m.Scope != "" {
+		fmt.Fprintf(&b, "(%s)", m.Scope)
+	}
+	if m.Bang {
+		b.WriteString("!")
+	}
+	b.WriteString(": " + m.Subject)
+	return b.String()
+}
+
+// String renders the message back, see [Message.FormatHeader].
+func (m Message) String() string {
+	var b strings.Builder
+	b.WriteString(m.FormatHeader())
+	if m.Body != "" {
+		b.WriteString("\n\n" + m.Body)
+	}
+	if len(m.Footers) > 0 {
+		b.WriteString("\n")
+		for _, f := range m.Footers {
+			b.WriteString("\n" + f.String())
+		}
+	}
+	return b.String()
+}
+
+var (
+	ErrEmpty  = errors.New("empty commit message")
+	ErrHeader = errors.New("header is not <type>(<scope>): <subject>")
+)
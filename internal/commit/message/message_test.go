@@ -0,0 +1,82 @@
+package message
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		input         string
+		expected      Message
+		expectedError error
+	}{
+		{
+			name:  "header only",
+			input: "fix: handle empty diff\n",
+			expected: Message{
+				Header: "fix: handle empty diff", Type: "fix",
+				Subject: "handle empty diff", Separated: true,
+			},
+		},
+		{
+			name: "body and footers",
+			input: "feat(api)!: Add pagination\n\nLists were unbounded.\n\nSecond paragraph.\n\n" +
+				"BREAKING CHANGE: list endpoints return a page\n  and a cursor\nRefs #12\n",
+			expected: Message{
+				Header: "feat(api)!: Add pagination", Type: "feat", Scope: "api",
+				Subject: "Add pagination", Breaking: true, Bang: true, Separated: true,
+				Body: "Lists were unbounded.\n\nSecond paragraph.",
+				Footers: []Footer{
+					{Token: BreakingChange, Value: "list endpoints return a page\n  and a cursor"},
+					{Token: "Refs", Value: "#12"},
+				},
+			},
+		},
+		{
+			name:  "breaking footer",
+			input: "refactor: Rename client\n\nBREAKING-CHANGE: the client is renamed",
+			expected: Message{
+				Header: "refactor: Rename client", Type: "refactor",
+				Subject: "Rename client", Breaking: true, Separated: true,
+				Footers: []Footer{{Token: BreakingChangeAlias, Value: "the client is renamed"}},
+			},
+		},
+		{
+			name:  "not conventional",
+			input: "Update things\nwithout a blank line",
+			expected: Message{
+				Header: "Update things", Body: "without a blank line",
+			},
+			expectedError: ErrHeader,
+		},
+		{
+			name:          "empty",
+			input:         " \n\n",
+			expectedError: ErrEmpty,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.input)
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("expected error %v got %v", test.expectedError, err)
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Fatalf("expected\n%#v\ngot\n%#v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestMessageString(t *testing.T) {
+	const s = "feat(api)!: Add pagination\n\nLists were unbounded.\n\nRefs #12\nReviewed-by: someone"
+	m, err := Parse(s)
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+	if m.String() != s {
+		t.Fatalf("expected\n%s\ngot\n%s", s, m.String())
+	}
+}
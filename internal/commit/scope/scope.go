@@ -1,6 +1,7 @@
 package scope
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/4sp1/yac/internal/snake"
@@ -78,3 +79,23 @@ func (s Scope) Flag() string {
 func (s Scope) Label() string {
 	return fmt.Sprintf("%q related commit", snake.Case(s.String(), snake.WithSpace()))
 }
+
+// Name is the scope as written in a commit header, empty for Other.
+func (s Scope) Name() string {
+	if s == Other {
+		return ""
+	}
+	return snake.Case(s.String())
+}
+
+// Parse returns the scope called name, Other when name is empty.
+func Parse(name string) (Scope, error) {
+	for s := range UpperBound {
+		if s.Name() == name {
+			return s, nil
+		}
+	}
+	return Other, fmt.Errorf("%w %q", ErrUnknown, name)
+}
+
+var ErrUnknown = errors.New("unknown scope")
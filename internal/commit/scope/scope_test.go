@@ -0,0 +1,21 @@
+package scope
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for s := range UpperBound {
+		got, err := Parse(s.Name())
+		if err != nil || got != s {
+			t.Fatalf("parse %q: expected %s got %s %v", s.Name(), s, got, err)
+		}
+	}
+	if got, _ := Parse("release-candidate"); got != ReleaseCandidate {
+		t.Fatalf("expected %s got %s", ReleaseCandidate, got)
+	}
+	if _, err := Parse("unknown"); !errors.Is(err, ErrUnknown) {
+		t.Fatalf("expected %q got %v", ErrUnknown, err)
+	}
+}
@@ -0,0 +1,178 @@
+// Package history archives the prompts sent to a provider along with their
+// answer, so that runs can be listed, compared and replayed.
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/4sp1/yac/internal/provider"
+)
+
+const recordExt = ".json"
+
+// Dir is the history directory of the repository at root, in the user cache
+// directory so that the archived prompts, holding whole diffs, stay out of
+// the working tree.
+func Dir(root string) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("user cache dir: %w", err)
+	}
+	sum := sha256.Sum256([]byte(root))
+	name := fmt.Sprintf("%s-%x", filepath.Base(root), sum[:4])
+	return filepath.Join(cache, "yac", "history", name), nil
+}
+
+// Record is an archived run.
+type Record struct {
+	Tag         string             `json:"tag"`
+	Time        time.Time          `json:"time"`
+	System      string             `json:"system,omitempty"`
+	Messages    []provider.Message `json:"messages"`
+	Temperature float64            `json:"temperature,omitempty"`
+	// Config holds the raw flags file the run was configured with.
+	Config   string            `json:"config,omitempty"`
+	Response provider.Response `json:"response"`
+	Latency  time.Duration     `json:"latency"`
+	// Message is the commit message written out of the response, once
+	// scoped and formatted, empty when it is the response text.
+	Message string `json:"message,omitempty"`
+}
+
+// NewRecord archives the req answered by res.
+func NewRecord(tag string, req provider.Request, res provider.Response,
+	latency time.Duration) Record {
+	return Record{
+		Tag:         tag,
+		Time:        time.Now(),
+		System:      req.System,
+		Messages:    req.Messages,
+		Temperature: req.Temperature,
+		Response:    res,
+		Latency:     latency,
+	}
+}
+
+// Request rebuilds the archived provider request.
+func (r Record) Request() provider.Request {
+	return provider.Request{
+		System:      r.System,
+		Messages:    r.Messages,
+		Temperature: r.Temperature,
+	}
+}
+
+// Subject is the first line of the archived commit message.
+func (r Record) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(r.CommitMessage()), "\n")
+	return subject
+}
+
+// CommitMessage is the commit message written out of the response.
+func (r Record) CommitMessage() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return r.Response.Text
+}
+
+// Prompt renders the archived prompt as plain text, for reading and diffing.
+func (r Record) Prompt() string {
+	var b strings.Builder
+	if r.System != "" {
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", provider.RoleSystem, r.System)
+	}
+	for _, m := range r.Messages {
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", m.Role, m.Text)
+	}
+	return b.String()
+}
+
+// Save writes r in dir, named after its tag, and returns its path.
+func Save(dir string, r Record) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("mkdir history: %w", err)
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode record: %w", err)
+	}
+	name := r.Tag
+	for i := 1; ; i++ {
+		path := filepath.Join(dir, name+recordExt)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			// several runs within the same tag second
+			name = fmt.Sprintf("%s-%d", r.Tag, i)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("create record: %w", err)
+		}
+		if _, err := f.Write(b); err != nil {
+			_ = f.Close()
+			return "", fmt.Errorf("write record: %w", err)
+		}
+		return path, f.Close()
+	}
+}
+
+// Load reads the record saved under name in dir.
+func Load(dir, name string) (Record, error) {
+	var r Record
+	b, err := os.ReadFile(filepath.Join(dir, strings.TrimSuffix(name, recordExt)+recordExt))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return r, fmt.Errorf("%w %q", ErrNotFound, name)
+		}
+		return r, fmt.Errorf("read record: %w", err)
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return r, fmt.Errorf("decode record %q: %w", name, err)
+	}
+	return r, nil
+}
+
+// Entry is a saved record and the name to load it with.
+type Entry struct {
+	Name string
+	Record
+}
+
+// List returns the records saved in dir, oldest first. A missing dir has no
+// records.
+func List(dir string) ([]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	var entries []Entry
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), recordExt)
+		if !ok || f.IsDir() {
+			continue
+		}
+		r, err := Load(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{Name: name, Record: r})
+	}
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return a.Time.Compare(b.Time)
+	})
+	return entries, nil
+}
+
+var ErrNotFound = errors.New("no such record")
@@ -0,0 +1,70 @@
+package history
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/4sp1/yac/internal/provider"
+)
+
+func TestSaveLoadList(t *testing.T) {
+	dir := t.TempDir()
+	if entries, err := List(dir + "/missing"); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no records, got %v %v", entries, err)
+	}
+
+	req := provider.NewRequest("some prompt")
+	req.System = "some rules"
+	first := NewRecord("root.dev-1", req, provider.Response{Text: "feat: one\n\nbody"}, time.Second)
+	second := first
+	second.Time = first.Time.Add(time.Minute)
+	second.Response.Text = "fix: two"
+	for _, r := range []Record{second, first} {
+		if _, err := Save(dir, r); err != nil {
+			t.Fatalf("save: %s", err)
+		}
+	}
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatalf("list: %s", err)
+	}
+	if len(entries) != 2 || entries[0].Subject() != "feat: one" || entries[1].Subject() != "fix: two" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if entries[0].Name != "root.dev-1-1" || entries[1].Name != "root.dev-1" {
+		t.Fatalf("unexpected names %q %q", entries[0].Name, entries[1].Name)
+	}
+
+	r, err := Load(dir, entries[1].Name)
+	if err != nil {
+		t.Fatalf("load: %s", err)
+	}
+	if got := r.Request(); got.System != "some rules" || got.Messages[0].Text != "some prompt" {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	if !strings.Contains(r.Prompt(), "## system\n\nsome rules\n\n## user\n\nsome prompt") {
+		t.Fatalf("unexpected prompt %q", r.Prompt())
+	}
+	if _, err := Load(dir, "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected %q got %v", ErrNotFound, err)
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	a, err := Dir("/src/yac")
+	if err != nil {
+		t.Fatalf("dir: %s", err)
+	}
+	b, err := Dir("/other/yac")
+	if err != nil {
+		t.Fatalf("dir: %s", err)
+	}
+	if a == b || !strings.Contains(a, "yac-") {
+		t.Fatalf("unexpected history dirs %q %q", a, b)
+	}
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultAnthropicModel   = "claude-sonnet-4-5-20250929"
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+	AnthropicVersion        = "2023-06-01"
+)
+
+// anthropic talks to the public Messages API with an API key, for those who
+// have no Google Cloud project at hand.
+type anthropic struct {
+	apiKey    string
+	model     string
+	baseURL   string
+	maxTokens int
+
+	logger *zap.Logger
+}
+
+func newAnthropic(s Settings) (Provider, error) {
+	if s.apiKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+	a := &anthropic{
+		apiKey:    s.apiKey,
+		model:     DefaultAnthropicModel,
+		baseURL:   DefaultAnthropicBaseURL,
+		maxTokens: s.maxTokens,
+		logger:    s.logger.Named("anthropic"),
+	}
+	if s.model != "" {
+		a.model = s.model
+	}
+	if s.baseURL != "" {
+		a.baseURL = strings.TrimSuffix(s.baseURL, "/")
+	}
+	return a, nil
+}
+
+func (a *anthropic) String() string {
+	return fmt.Sprintf("anthropic %s", a.model)
+}
+
+func (a *anthropic) Complete(ctx context.Context, r Request) (res Response, err error) {
+	url := a.baseURL + "/v1/messages"
+
+	maxTokens := a.maxTokens
+	if r.MaxTokens > 0 {
+		maxTokens = r.MaxTokens
+	}
+
+	payload := struct {
+		Model       string      `json:"model"`
+		System      string      `json:"system,omitempty"`
+		Messages    []claudeMsg `json:"messages"`
+		MaxTokens   int         `json:"max_tokens"`
+		Temperature float64     `json:"temperature,omitempty"`
+		Stream      bool        `json:"stream,omitempty"`
+	}{
+		Model:       a.model,
+		System:      r.System,
+		Messages:    newClaudeMsgs(r.Messages),
+		MaxTokens:   maxTokens,
+		Temperature: r.Temperature,
+		Stream:      r.Stream != nil,
+	}
+
+	var buf bytes.Buffer
+	if err = json.NewEncoder(&buf).Encode(payload); err != nil {
+		return res, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+	if err != nil {
+		return res, err
+	}
+	req.Header.Add("x-api-key", a.apiKey)
+	req.Header.Add("anthropic-version", AnthropicVersion)
+	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+
+	a.logger.Debug("new request for anthropic api",
+		zap.String("url", url),
+		zap.String("model", a.model),
+		zap.Int("max_tokens", maxTokens))
+
+	hres, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if cerr := hres.Body.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	a.logger.Debug("anthropic api response returned",
+		zap.String("status", hres.Status))
+
+	if err = checkStatus(hres); err != nil {
+		return res, err
+	}
+
+	body, err := decodeClaude(hres.Body, r.Stream)
+	if err != nil {
+		return res, err
+	}
+	return body.response()
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnthropicComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "secret" {
+			t.Errorf("unexpected api key %q", got)
+		}
+		var payload struct {
+			Model    string `json:"model"`
+			System   string `json:"system"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %s", err)
+		}
+		if len(payload.Messages) != 1 || payload.Messages[0].Content[0].Text != "some prompt" {
+			t.Errorf("unexpected messages %+v", payload.Messages)
+		}
+		if payload.System != "some rules" {
+			t.Errorf("unexpected system %q", payload.System)
+		}
+		_, _ = w.Write([]byte(`{
+			"model": "` + payload.Model + `",
+			"content": [{"type": "text", "text": "feat(api): add things"}]
+		}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Anthropic, WithAPIKey("secret"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+	req := NewRequest("some prompt")
+	req.System = "some rules"
+	res, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if res.Text != "feat(api): add things" {
+		t.Fatalf("unexpected text %q", res.Text)
+	}
+	if res.Model != DefaultAnthropicModel {
+		t.Fatalf("unexpected model %q", res.Model)
+	}
+}
+
+func TestAnthropicMissingKey(t *testing.T) {
+	if _, err := New(Anthropic); !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatalf("expected %q got %v", ErrMissingAPIKey, err)
+	}
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// chain falls through an ordered list of providers until one of them answers.
+type chain struct {
+	providers []Provider
+
+	logger *zap.Logger
+}
+
+// NewChain returns a provider trying each of the given providers in order.
+func NewChain(logger *zap.Logger, providers ...Provider) (Provider, error) {
+	if len(providers) == 0 {
+		return nil, ErrEmptyChain
+	}
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &chain{
+		providers: providers,
+		logger:    logger.Named("chain"),
+	}, nil
+}
+
+func (c *chain) String() string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.String()
+	}
+	return strings.Join(names, " -> ")
+}
+
+func (c *chain) Complete(ctx context.Context, r Request) (Response, error) {
+	var streamed *countingWriter
+	if r.Stream != nil {
+		streamed = &countingWriter{w: r.Stream}
+		r.Stream = streamed
+	}
+	var errs []error
+	for i, p := range c.providers {
+		res, err := p.Complete(ctx, r)
+		if err == nil {
+			c.logger.Debug("provider answered",
+				zap.Stringer("provider", p),
+				zap.Int("rank", i))
+			return res, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p, err))
+		if errors.Is(err, context.Canceled) {
+			break
+		}
+		c.logger.Warn("provider failed, falling through",
+			zap.Stringer("provider", p),
+			zap.Error(err))
+		if streamed != nil && streamed.n > 0 {
+			// keep the failed partial answer apart from the next one
+			if _, err := io.WriteString(streamed.w, "\n\n"); err != nil {
+				return Response{}, err
+			}
+			streamed.n = 0
+		}
+	}
+	return Response{}, errors.Join(errs...)
+}
+
+var ErrEmptyChain = errors.New("empty provider chain")
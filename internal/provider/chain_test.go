@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	first := &flaky{errs: []error{&APIError{Status: 403}}}
+	second := &flaky{}
+	p, err := NewChain(nil, first, second)
+	if err != nil {
+		t.Fatalf("new chain: %s", err)
+	}
+	res, err := p.Complete(context.Background(), NewRequest("prompt"))
+	if err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if res.Text != "feat: ok" || first.calls != 1 || second.calls != 1 {
+		t.Fatalf("unexpected %q after %d, %d calls", res.Text, first.calls, second.calls)
+	}
+}
+
+func TestChainExhausted(t *testing.T) {
+	first := &flaky{errs: []error{&APIError{Status: 403}}}
+	second := &flaky{errs: []error{ErrRefusal}}
+	p, err := NewChain(nil, first, second)
+	if err != nil {
+		t.Fatalf("new chain: %s", err)
+	}
+	_, err = p.Complete(context.Background(), NewRequest("prompt"))
+	if !errors.Is(err, ErrAuth) || !errors.Is(err, ErrRefusal) {
+		t.Fatalf("expected every provider error got %v", err)
+	}
+}
+
+func TestEmptyChain(t *testing.T) {
+	if _, err := NewChain(nil); !errors.Is(err, ErrEmptyChain) {
+		t.Fatalf("expected %q got %v", ErrEmptyChain, err)
+	}
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type claudeMsg struct {
+	Role    string             `json:"role"`
+	Content []claudeMsgContent `json:"content"`
+}
+
+type claudeMsgContent interface {
+	fmt.Stringer
+	json.Marshaler
+}
+
+func newClaudeMsgTxt(text string) claudeMsgContent {
+	return claudeMsgTxt{
+		Type: "text",
+		Text: text,
+	}
+}
+
+func (c claudeMsgTxt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}{
+		Type: c.Type,
+		Text: c.Text,
+	})
+}
+
+func (cmc claudeMsgTxt) String() string {
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(cmc); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
+
+type claudeMsgTxt struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func newClaudeMsgs(messages []Message) []claudeMsg {
+	msgs := make([]claudeMsg, len(messages))
+	for i, m := range messages {
+		msgs[i] = claudeMsg{
+			Role:    m.Role,
+			Content: []claudeMsgContent{newClaudeMsgTxt(m.Text)},
+		}
+	}
+	return msgs
+}
+
+// claudeResponse is the Messages API answer shared by anthropic and vertex.
+type claudeResponse struct {
+	Model      string               `json:"model"`
+	Content    []claudeContentBlock `json:"content"`
+	StopReason string               `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type claudeContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (r claudeResponse) text() string {
+	var b strings.Builder
+	for _, c := range r.Content {
+		if c.Type == "text" {
+			b.WriteString(c.Text)
+		}
+	}
+	return b.String()
+}
+
+// decodeClaude decodes either a plain json answer or, when w is set, an event
+// stream forwarding text deltas to w.
+func decodeClaude(r io.Reader, w io.Writer) (claudeResponse, error) {
+	if w != nil {
+		return decodeClaudeStream(r, w)
+	}
+	var body claudeResponse
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return body, fmt.Errorf("decode response: %w", err)
+	}
+	return body, nil
+}
+
+// response converts the answer, reporting refusals and truncations as errors
+// along with the partial response.
+func (r claudeResponse) response() (Response, error) {
+	res := Response{
+		Text:       r.text(),
+		Model:      r.Model,
+		StopReason: r.StopReason,
+		Usage: Usage{
+			InputTokens:  r.Usage.InputTokens,
+			OutputTokens: r.Usage.OutputTokens,
+		},
+	}
+	switch r.StopReason {
+	case "max_tokens":
+		return res, fmt.Errorf("%w after %d output tokens", ErrTruncated, res.Usage.OutputTokens)
+	case "refusal":
+		return res, ErrRefusal
+	}
+	if strings.TrimSpace(res.Text) == "" {
+		return res, ErrEmpty
+	}
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingAPIKey  = errors.New("missing api key")
+	ErrMissingProject = errors.New("missing google cloud project")
+	ErrNoChoice       = errors.New("no choice in response")
+	ErrEmpty          = errors.New("empty response")
+
+	// ErrAuth, ErrQuota and ErrOverloaded are matched by [APIError] with
+	// [errors.Is] depending on the http status.
+	ErrAuth       = errors.New("authentication failed")
+	ErrQuota      = errors.New("quota exceeded")
+	ErrOverloaded = errors.New("overloaded")
+
+	// ErrRefusal and ErrTruncated are reported along with the partial
+	// [Response] so the caller can decide what to do with it.
+	ErrRefusal   = errors.New("refused by safety filter")
+	ErrTruncated = errors.New("truncated by max tokens")
+)
+
+// APIError is returned when the provider endpoint answers with a non 2xx
+// status.
+type APIError struct {
+	Status  int
+	Type    string
+	Message string
+	// RetryAfter is the delay asked by the endpoint, if any.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "api error %d %s", e.Status, http.StatusText(e.Status))
+	if e.Type != "" {
+		fmt.Fprintf(&b, " (%s)", e.Type)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	return b.String()
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return e.Status == http.StatusUnauthorized ||
+			e.Status == http.StatusForbidden
+	case ErrQuota:
+		return e.Status == http.StatusTooManyRequests
+	case ErrOverloaded:
+		// 529 is the anthropic overloaded status
+		return e.Status == 529 ||
+			e.Status == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// checkStatus returns an [APIError] decoded from the response body when the
+// status is not 2xx. The anthropic, google and openai error shapes are
+// understood; anything else is kept verbatim as the message.
+func checkStatus(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	apiErr := &APIError{
+		Status:     res.StatusCode,
+		RetryAfter: parseRetryAfter(res.Header, time.Now()),
+	}
+	raw, err := io.ReadAll(io.LimitReader(res.Body, 1<<16))
+	if err != nil {
+		return apiErr
+	}
+	// google apis sometimes wrap the error in a single element list
+	trimmed := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(trimmed, "[") {
+		trimmed = strings.TrimSuffix(strings.TrimPrefix(trimmed, "["), "]")
+	}
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(trimmed), &body); err != nil || len(body.Error) == 0 {
+		apiErr.Message = trimmed
+		return apiErr
+	}
+	var detail struct {
+		Type    string `json:"type"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body.Error, &detail); err != nil {
+		// ollama answers with {"error": "message"}
+		var msg string
+		if err := json.Unmarshal(body.Error, &msg); err == nil {
+			apiErr.Message = msg
+		}
+		return apiErr
+	}
+	apiErr.Type = detail.Type
+	if apiErr.Type == "" {
+		apiErr.Type = detail.Status
+	}
+	apiErr.Message = detail.Message
+	return apiErr
+}
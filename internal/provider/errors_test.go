@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckStatus(t *testing.T) {
+	for _, test := range []struct {
+		name            string
+		status          int
+		body            string
+		expectedType    string
+		expectedMessage string
+		expectedError   error
+	}{
+		{
+			name:            "anthropic rate limit",
+			status:          http.StatusTooManyRequests,
+			body:            `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`,
+			expectedType:    "rate_limit_error",
+			expectedMessage: "slow down",
+			expectedError:   ErrQuota,
+		},
+		{
+			name:            "google permission denied",
+			status:          http.StatusForbidden,
+			body:            `[{"error":{"code":403,"message":"no access","status":"PERMISSION_DENIED"}}]`,
+			expectedType:    "PERMISSION_DENIED",
+			expectedMessage: "no access",
+			expectedError:   ErrAuth,
+		},
+		{
+			name:            "anthropic overloaded",
+			status:          529,
+			body:            `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			expectedType:    "overloaded_error",
+			expectedMessage: "Overloaded",
+			expectedError:   ErrOverloaded,
+		},
+		{
+			name:            "ollama",
+			status:          http.StatusNotFound,
+			body:            `{"error":"model not found"}`,
+			expectedMessage: "model not found",
+		},
+		{
+			name:            "plain text",
+			status:          http.StatusBadGateway,
+			body:            "bad gateway\n",
+			expectedMessage: "bad gateway",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rec.WriteHeader(test.status)
+			_, _ = rec.WriteString(test.body)
+			err := checkStatus(rec.Result())
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected api error got %v", err)
+			}
+			if apiErr.Type != test.expectedType {
+				t.Errorf("expected type %q got %q", test.expectedType, apiErr.Type)
+			}
+			if apiErr.Message != test.expectedMessage {
+				t.Errorf("expected message %q got %q", test.expectedMessage, apiErr.Message)
+			}
+			if test.expectedError != nil && !errors.Is(err, test.expectedError) {
+				t.Errorf("expected %q got %q", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestClaudeStopReason(t *testing.T) {
+	for _, test := range []struct {
+		body          string
+		expectedError error
+	}{
+		{
+			body: `{"content":[{"type":"text","text":"feat: x"}],"stop_reason":"end_turn"}`,
+		},
+		{
+			body:          `{"content":[{"type":"text","text":"feat: x"}],"stop_reason":"max_tokens"}`,
+			expectedError: ErrTruncated,
+		},
+		{
+			body:          `{"content":[],"stop_reason":"refusal"}`,
+			expectedError: ErrRefusal,
+		},
+		{
+			body:          `{"content":[],"stop_reason":"end_turn"}`,
+			expectedError: ErrEmpty,
+		},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(test.body))
+		}))
+		p, err := New(Anthropic, WithAPIKey("secret"), WithBaseURL(srv.URL))
+		if err != nil {
+			t.Fatalf("new: %s", err)
+		}
+		_, err = p.Complete(context.Background(), NewRequest("some prompt"))
+		srv.Close()
+		if !errors.Is(err, test.expectedError) {
+			t.Errorf("%s: expected %v got %v", test.body, test.expectedError, err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	googleScope    = "https://www.googleapis.com/auth/cloud-platform"
+	googleTokenURI = "https://oauth2.googleapis.com/token"
+
+	// tokens are renewed a bit before their expiry so that a request never
+	// starts with a token about to expire
+	googleTokenSkew = time.Minute
+	// gcloud doesn't tell the expiry of the token it prints
+	gcloudTokenLifetime = 5 * time.Minute
+)
+
+// googleCredentials is the application default credentials json document,
+// either a service account key or the gcloud user credentials.
+type googleCredentials struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// findGoogleCredentials reads the credentials file at path or, when empty,
+// the gcloud application default credentials file. It returns nil when none
+// is found, or when the default one is of a type it cannot use, so the caller
+// can fall back to the gcloud command.
+func findGoogleCredentials(path string) (*googleCredentials, error) {
+	explicit := path != ""
+	if !explicit {
+		dir := gcloudConfigDir()
+		if dir == "" {
+			return nil, nil
+		}
+		path = filepath.Join(dir, "application_default_credentials.json")
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read credentials: %w", err)
+	}
+	var c googleCredentials
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("decode credentials %q: %w", path, err)
+	}
+	switch c.Type {
+	case "service_account", "authorized_user":
+	default:
+		if !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w %q in %q", ErrCredentialsType, c.Type, path)
+	}
+	if c.TokenURI == "" {
+		c.TokenURI = googleTokenURI
+	}
+	return &c, nil
+}
+
+// gcloudConfigDir is where gcloud keeps its configuration: $CLOUDSDK_CONFIG,
+// else %APPDATA%/gcloud on windows and ~/.config/gcloud elsewhere, macOS
+// included. It is empty when none can be told.
+func gcloudConfigDir() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "gcloud")
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud")
+}
+
+// googleToken caches an access token until its expiry.
+type googleToken struct {
+	creds *googleCredentials
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func (gt *googleToken) token(ctx context.Context) (string, error) {
+	gt.mu.Lock()
+	defer gt.mu.Unlock()
+	if gt.accessToken != "" && gt.now().Add(googleTokenSkew).Before(gt.expiry) {
+		return gt.accessToken, nil
+	}
+	var (
+		token    string
+		lifetime time.Duration
+		err      error
+	)
+	switch {
+	case gt.creds == nil:
+		token, lifetime, err = gcloudToken(ctx)
+	case gt.creds.Type == "service_account":
+		token, lifetime, err = gt.serviceAccountToken(ctx)
+	default:
+		token, lifetime, err = gt.refreshToken(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+	gt.accessToken = token
+	gt.expiry = gt.now().Add(lifetime)
+	gt.logger.Debug("googlecloud aiplatform token retrieved",
+		zap.Time("expiry", gt.expiry))
+	return gt.accessToken, nil
+}
+
+func gcloudToken(ctx context.Context) (string, time.Duration, error) {
+	out, err := exec.CommandContext(ctx, "gcloud", "auth", "print-access-token").Output()
+	if err != nil {
+		return "", 0, fmt.Errorf("gcloud: %w", err)
+	}
+	return strings.TrimSpace(string(out)), gcloudTokenLifetime, nil
+}
+
+func (gt *googleToken) serviceAccountToken(ctx context.Context) (string, time.Duration, error) {
+	assertion, err := gt.assertion()
+	if err != nil {
+		return "", 0, fmt.Errorf("service account assertion: %w", err)
+	}
+	return gt.exchange(ctx, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+}
+
+func (gt *googleToken) refreshToken(ctx context.Context) (string, time.Duration, error) {
+	return gt.exchange(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {gt.creds.ClientID},
+		"client_secret": {gt.creds.ClientSecret},
+		"refresh_token": {gt.creds.RefreshToken},
+	})
+}
+
+// assertion signs the service account jwt exchanged for an access token.
+func (gt *googleToken) assertion() (string, error) {
+	block, _ := pem.Decode([]byte(gt.creds.PrivateKey))
+	if block == nil {
+		return "", ErrPrivateKey
+	}
+	var key *rsa.PrivateKey
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		var ok bool
+		if key, ok = k.(*rsa.PrivateKey); !ok {
+			return "", fmt.Errorf("%w: %T not rsa", ErrPrivateKey, k)
+		}
+	} else if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPrivateKey, err)
+	}
+	iat := gt.now()
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": gt.creds.PrivateKeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   gt.creds.ClientEmail,
+		"scope": googleScope,
+		"aud":   gt.creds.TokenURI,
+		"iat":   iat.Unix(),
+		"exp":   iat.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+func (gt *googleToken) exchange(ctx context.Context, form url.Values) (token string, lifetime time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gt.creds.TokenURI,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		if cerr := res.Body.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	if err = checkStatus(res); err != nil {
+		return "", 0, fmt.Errorf("token exchange: %w", err)
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("decode token: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", 0, fmt.Errorf("token exchange: %w", ErrEmpty)
+	}
+	return body.AccessToken, time.Duration(body.ExpiresIn) * time.Second, nil
+}
+
+var (
+	ErrCredentialsType = errors.New("unsupported credentials type")
+	ErrPrivateKey      = errors.New("invalid private key")
+)
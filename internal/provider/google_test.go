@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestGoogleServiceAccountToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %s", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %s", err)
+	}
+
+	var exchanges int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %s", err)
+		}
+		if got := r.Form.Get("grant_type"); got != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("unexpected grant type %q", got)
+		}
+		if parts := strings.Split(r.Form.Get("assertion"), "."); len(parts) != 3 {
+			t.Errorf("unexpected assertion %q", r.Form.Get("assertion"))
+		}
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	b, err := json.Marshal(googleCredentials{
+		Type:         "service_account",
+		ProjectID:    "some-project",
+		PrivateKeyID: "kid",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "yac@some-project.iam.gserviceaccount.com",
+		TokenURI:     srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("marshal credentials: %s", err)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatalf("write credentials: %s", err)
+	}
+
+	creds, err := findGoogleCredentials(path)
+	if err != nil {
+		t.Fatalf("find credentials: %s", err)
+	}
+	now := time.Now()
+	gt := &googleToken{
+		creds:  creds,
+		now:    func() time.Time { return now },
+		logger: zap.NewNop(),
+	}
+	for range 2 {
+		token, err := gt.token(context.Background())
+		if err != nil {
+			t.Fatalf("token: %s", err)
+		}
+		if token != "token" {
+			t.Fatalf("unexpected token %q", token)
+		}
+	}
+	if exchanges != 1 {
+		t.Fatalf("expected cached token, got %d exchanges", exchanges)
+	}
+	now = now.Add(time.Hour)
+	if _, err := gt.token(context.Background()); err != nil {
+		t.Fatalf("token: %s", err)
+	}
+	if exchanges != 2 {
+		t.Fatalf("expected expired token renewal, got %d exchanges", exchanges)
+	}
+}
+
+func TestGoogleAuthorizedUserToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %s", err)
+		}
+		for field, expected := range map[string]string{
+			"grant_type":    "refresh_token",
+			"client_id":     "some-client",
+			"client_secret": "some-secret",
+			"refresh_token": "some-refresh-token",
+		} {
+			if got := r.Form.Get(field); got != expected {
+				t.Errorf("unexpected %s %q", field, got)
+			}
+		}
+		_, _ = w.Write([]byte(`{"access_token":"user-token","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	// the application default credentials are looked up in CLOUDSDK_CONFIG
+	dir := t.TempDir()
+	t.Setenv("CLOUDSDK_CONFIG", dir)
+	b, err := json.Marshal(googleCredentials{
+		Type:         "authorized_user",
+		ClientID:     "some-client",
+		ClientSecret: "some-secret",
+		RefreshToken: "some-refresh-token",
+		TokenURI:     srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("marshal credentials: %s", err)
+	}
+	path := filepath.Join(dir, "application_default_credentials.json")
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatalf("write credentials: %s", err)
+	}
+
+	creds, err := findGoogleCredentials("")
+	if err != nil {
+		t.Fatalf("find credentials: %s", err)
+	}
+	if creds == nil || creds.Type != "authorized_user" {
+		t.Fatalf("unexpected credentials %+v", creds)
+	}
+	gt := &googleToken{
+		creds:  creds,
+		now:    time.Now,
+		logger: zap.NewNop(),
+	}
+	token, err := gt.token(context.Background())
+	if err != nil {
+		t.Fatalf("token: %s", err)
+	}
+	if token != "user-token" {
+		t.Fatalf("unexpected token %q", token)
+	}
+}
+
+func TestFindGoogleCredentialsType(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOUDSDK_CONFIG", dir)
+	b, err := json.Marshal(googleCredentials{Type: "external_account"})
+	if err != nil {
+		t.Fatalf("marshal credentials: %s", err)
+	}
+	path := filepath.Join(dir, "application_default_credentials.json")
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatalf("write credentials: %s", err)
+	}
+
+	// the default credentials give way to the gcloud command
+	creds, err := findGoogleCredentials("")
+	if err != nil || creds != nil {
+		t.Fatalf("unexpected credentials %+v %v", creds, err)
+	}
+	if _, err := findGoogleCredentials(path); !errors.Is(err, ErrCredentialsType) {
+		t.Fatalf("expected %v got %v", ErrCredentialsType, err)
+	}
+}
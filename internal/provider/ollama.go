@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultOllamaModel   = "llama3.2"
+	DefaultOllamaBaseURL = "http://localhost:11434"
+)
+
+// ollama talks to a local (or remote) ollama server through its /api/chat
+// endpoint.
+type ollama struct {
+	model     string
+	baseURL   string
+	maxTokens int
+
+	logger *zap.Logger
+}
+
+func newOllama(s Settings) (Provider, error) {
+	o := &ollama{
+		model:     DefaultOllamaModel,
+		baseURL:   DefaultOllamaBaseURL,
+		maxTokens: s.maxTokens,
+		logger:    s.logger.Named("ollama"),
+	}
+	if s.model != "" {
+		o.model = s.model
+	}
+	if s.baseURL != "" {
+		o.baseURL = strings.TrimSuffix(s.baseURL, "/")
+		// OLLAMA_HOST is commonly set without a scheme.
+		if !strings.Contains(o.baseURL, "://") {
+			o.baseURL = "http://" + o.baseURL
+		}
+	}
+	return o, nil
+}
+
+func (o *ollama) String() string {
+	return fmt.Sprintf("ollama %s", o.model)
+}
+
+type ollamaMsg struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func (o *ollama) Complete(ctx context.Context, r Request) (res Response, err error) {
+	url := o.baseURL + "/api/chat"
+
+	maxTokens := o.maxTokens
+	if r.MaxTokens > 0 {
+		maxTokens = r.MaxTokens
+	}
+
+	msgs := make([]ollamaMsg, 0, len(r.Messages)+1)
+	if r.System != "" {
+		msgs = append(msgs, ollamaMsg{Role: RoleSystem, Content: r.System})
+	}
+	for _, m := range r.Messages {
+		msgs = append(msgs, ollamaMsg{Role: m.Role, Content: m.Text})
+	}
+	payload := struct {
+		Model    string      `json:"model"`
+		Messages []ollamaMsg `json:"messages"`
+		Stream   bool        `json:"stream"`
+		Options  struct {
+			NumCtx      int     `json:"num_ctx"`
+			NumPredict  int     `json:"num_predict"`
+			Temperature float64 `json:"temperature,omitempty"`
+		} `json:"options"`
+	}{
+		Model:    o.model,
+		Messages: msgs,
+		Stream:   false,
+	}
+	// ollama truncates the prompt to its own small default context unless
+	// asked for the model's window, the one the prompt is budgeted against
+	payload.Options.NumCtx = ContextWindow(Ollama, o.model)
+	payload.Options.NumPredict = maxTokens
+	payload.Options.Temperature = r.Temperature
+
+	var buf bytes.Buffer
+	if err = json.NewEncoder(&buf).Encode(payload); err != nil {
+		return res, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+	if err != nil {
+		return res, err
+	}
+	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+
+	o.logger.Debug("new request for ollama api",
+		zap.String("url", url),
+		zap.String("model", o.model),
+		zap.Int("num_ctx", payload.Options.NumCtx),
+		zap.Int("num_predict", maxTokens))
+
+	hres, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if cerr := hres.Body.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	o.logger.Debug("ollama api response returned",
+		zap.String("status", hres.Status))
+
+	if err = checkStatus(hres); err != nil {
+		return res, err
+	}
+
+	var body struct {
+		Model           string    `json:"model"`
+		Message         ollamaMsg `json:"message"`
+		DoneReason      string    `json:"done_reason"`
+		PromptEvalCount int       `json:"prompt_eval_count"`
+		EvalCount       int       `json:"eval_count"`
+	}
+	if err = json.NewDecoder(hres.Body).Decode(&body); err != nil {
+		return res, fmt.Errorf("decode response: %w", err)
+	}
+	res = Response{
+		Text:       body.Message.Content,
+		Model:      body.Model,
+		StopReason: body.DoneReason,
+		Usage: Usage{
+			InputTokens:  body.PromptEvalCount,
+			OutputTokens: body.EvalCount,
+		},
+	}
+	if err = flush(r, res); err != nil {
+		return res, err
+	}
+	if body.DoneReason == "length" {
+		return res, fmt.Errorf("%w after %d output tokens", ErrTruncated, body.EvalCount)
+	}
+	if strings.TrimSpace(res.Text) == "" {
+		return res, ErrEmpty
+	}
+	return res, nil
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOllamaComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		var payload struct {
+			Model    string      `json:"model"`
+			Messages []ollamaMsg `json:"messages"`
+			Stream   bool        `json:"stream"`
+			Options  struct {
+				NumCtx int `json:"num_ctx"`
+			} `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %s", err)
+		}
+		if payload.Stream {
+			t.Error("unexpected stream")
+		}
+		if payload.Options.NumCtx != 32_768 {
+			t.Errorf("unexpected num_ctx %d", payload.Options.NumCtx)
+		}
+		if len(payload.Messages) != 1 || payload.Messages[0].Content != "some prompt" {
+			t.Errorf("unexpected messages %+v", payload.Messages)
+		}
+		_, _ = w.Write([]byte(`{
+			"model": "` + payload.Model + `",
+			"message": {"role": "assistant", "content": "fix(db): close rows"},
+			"done": true
+		}`))
+	}))
+	defer srv.Close()
+
+	// OLLAMA_HOST style address without scheme
+	p, err := New(Ollama, WithModel("qwen2.5"), WithBaseURL(strings.TrimPrefix(srv.URL, "http://")))
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+	res, err := p.Complete(context.Background(), NewRequest("some prompt"))
+	if err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if res.Text != "fix(db): close rows" {
+		t.Fatalf("unexpected text %q", res.Text)
+	}
+	if res.Model != "qwen2.5" {
+		t.Fatalf("unexpected model %q", res.Model)
+	}
+}
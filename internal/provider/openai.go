@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultOpenaiModel = "gpt-4o-mini"
+	// DefaultOpenaiBaseURL includes the api version like the official SDKs
+	// do, so llama.cpp, vLLM or LM Studio servers are configured with their
+	// own "/v1" suffixed url.
+	DefaultOpenaiBaseURL = "https://api.openai.com/v1"
+)
+
+// openai speaks the chat completions wire format. The bearer token is
+// optional since most local servers don't check it.
+type openai struct {
+	apiKey    string
+	model     string
+	baseURL   string
+	maxTokens int
+
+	logger *zap.Logger
+}
+
+func newOpenai(s Settings) (Provider, error) {
+	o := &openai{
+		apiKey:    s.apiKey,
+		model:     DefaultOpenaiModel,
+		baseURL:   DefaultOpenaiBaseURL,
+		maxTokens: s.maxTokens,
+		logger:    s.logger.Named("openai"),
+	}
+	if s.model != "" {
+		o.model = s.model
+	}
+	if s.baseURL != "" {
+		o.baseURL = strings.TrimSuffix(s.baseURL, "/")
+	}
+	return o, nil
+}
+
+func (o *openai) String() string {
+	return fmt.Sprintf("openai %s", o.model)
+}
+
+type openaiMsg struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func (o *openai) Complete(ctx context.Context, r Request) (res Response, err error) {
+	url := o.baseURL + "/chat/completions"
+
+	maxTokens := o.maxTokens
+	if r.MaxTokens > 0 {
+		maxTokens = r.MaxTokens
+	}
+
+	msgs := make([]openaiMsg, 0, len(r.Messages)+1)
+	if r.System != "" {
+		msgs = append(msgs, openaiMsg{Role: RoleSystem, Content: r.System})
+	}
+	for _, m := range r.Messages {
+		msgs = append(msgs, openaiMsg{Role: m.Role, Content: m.Text})
+	}
+	payload := struct {
+		Model       string      `json:"model"`
+		Messages    []openaiMsg `json:"messages"`
+		MaxTokens   int         `json:"max_tokens"`
+		Temperature float64     `json:"temperature,omitempty"`
+	}{
+		Model:       o.model,
+		Messages:    msgs,
+		MaxTokens:   maxTokens,
+		Temperature: r.Temperature,
+	}
+
+	var buf bytes.Buffer
+	if err = json.NewEncoder(&buf).Encode(payload); err != nil {
+		return res, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+	if err != nil {
+		return res, err
+	}
+	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+	if o.apiKey != "" {
+		req.Header.Add("Authorization", "Bearer "+o.apiKey)
+	}
+
+	o.logger.Debug("new request for chat completions api",
+		zap.String("url", url),
+		zap.String("model", o.model),
+		zap.Int("max_tokens", maxTokens))
+
+	hres, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if cerr := hres.Body.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	o.logger.Debug("chat completions api response returned",
+		zap.String("status", hres.Status))
+
+	if err = checkStatus(hres); err != nil {
+		return res, err
+	}
+
+	var body struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message      openaiMsg `json:"message"`
+			FinishReason string    `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err = json.NewDecoder(hres.Body).Decode(&body); err != nil {
+		return res, fmt.Errorf("decode response: %w", err)
+	}
+	if len(body.Choices) == 0 {
+		return res, fmt.Errorf("decode response: %w", ErrNoChoice)
+	}
+	choice := body.Choices[0]
+	res = Response{
+		Text:       choice.Message.Content,
+		Model:      body.Model,
+		StopReason: choice.FinishReason,
+		Usage: Usage{
+			InputTokens:  body.Usage.PromptTokens,
+			OutputTokens: body.Usage.CompletionTokens,
+		},
+	}
+	if err = flush(r, res); err != nil {
+		return res, err
+	}
+	switch choice.FinishReason {
+	case "length":
+		return res, fmt.Errorf("%w after %d output tokens", ErrTruncated, res.Usage.OutputTokens)
+	case "content_filter":
+		return res, ErrRefusal
+	}
+	if strings.TrimSpace(res.Text) == "" {
+		return res, ErrEmpty
+	}
+	return res, nil
+}
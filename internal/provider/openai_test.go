@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenaiComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		var payload struct {
+			Model       string      `json:"model"`
+			Messages    []openaiMsg `json:"messages"`
+			Temperature float64     `json:"temperature"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %s", err)
+		}
+		if len(payload.Messages) != 2 ||
+			payload.Messages[0].Role != RoleSystem ||
+			payload.Messages[1].Content != "some prompt" {
+			t.Errorf("unexpected messages %+v", payload.Messages)
+		}
+		if payload.Temperature != 0.7 {
+			t.Errorf("unexpected temperature %g", payload.Temperature)
+		}
+		_, _ = w.Write([]byte(`{
+			"model": "` + payload.Model + `",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "docs: fix typo"}}]
+		}`))
+	}))
+	defer srv.Close()
+
+	p, err := New(Openai, WithAPIKey("token"), WithModel("local"), WithBaseURL(srv.URL+"/v1/"))
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+	req := NewRequest("some prompt")
+	req.System = "some rules"
+	req.Temperature = 0.7
+	res, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if res.Text != "docs: fix typo" {
+		t.Fatalf("unexpected text %q", res.Text)
+	}
+	if res.Model != "local" {
+		t.Fatalf("unexpected model %q", res.Model)
+	}
+}
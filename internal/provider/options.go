@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+type Option interface {
+	Apply(Settings) (Settings, error)
+	fmt.Stringer
+}
+
+// Settings is shared by every provider implementation; each one only reads
+// the fields that make sense for its endpoint.
+type Settings struct {
+	model     string
+	baseURL   string
+	apiKey    string
+	project   string
+	location  string
+	maxTokens int
+
+	credentials string
+	retry       RetryPolicy
+	fixtures    string
+
+	logger *zap.Logger
+}
+
+type optionFn func(Settings) (Settings, error)
+
+type option struct {
+	description string
+	apply       optionFn
+}
+
+var _ Option = &option{}
+
+func (opt option) Apply(s Settings) (Settings, error) {
+	return opt.apply(s)
+}
+
+func (opt option) String() string {
+	return opt.description
+}
+
+func WithLogger(log *zap.Logger) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			s.logger = log
+			return s, nil
+		},
+		description: "set logger",
+	}
+}
+
+// WithModel overrides the provider default model. An empty model keeps the
+// default.
+func WithModel(model string) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			if model != "" {
+				s.model = model
+			}
+			return s, nil
+		},
+		description: fmt.Sprintf("set model %q", model),
+	}
+}
+
+// WithBaseURL overrides the provider default endpoint. An empty url keeps the
+// default.
+func WithBaseURL(url string) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			if url != "" {
+				s.baseURL = url
+			}
+			return s, nil
+		},
+		description: fmt.Sprintf("set base url %q", url),
+	}
+}
+
+func WithAPIKey(key string) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			s.apiKey = key
+			return s, nil
+		},
+		description: "set api key",
+	}
+}
+
+func WithProject(project string) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			s.project = project
+			return s, nil
+		},
+		description: fmt.Sprintf("set project %q", project),
+	}
+}
+
+// WithCredentials reads google credentials from a service account key or
+// authorized user json file instead of the gcloud application default
+// credentials.
+func WithCredentials(path string) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			s.credentials = path
+			return s, nil
+		},
+		description: fmt.Sprintf("set credentials %q", path),
+	}
+}
+
+func WithLocation(location string) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			if location != "" {
+				s.location = location
+			}
+			return s, nil
+		},
+		description: fmt.Sprintf("set location %q", location),
+	}
+}
+
+func WithMaxTokens(n int) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			if n <= 0 {
+				return s, fmt.Errorf("max tokens must be positive, got %d", n)
+			}
+			s.maxTokens = n
+			return s, nil
+		},
+		description: fmt.Sprintf("set max tokens %d", n),
+	}
+}
+
+// WithRetry sets the retry and timeout policy wrapping the provider calls.
+func WithRetry(policy RetryPolicy) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			if policy.MaxAttempts < 1 {
+				return s, fmt.Errorf("max attempts must be positive, got %d", policy.MaxAttempts)
+			}
+			s.retry = policy
+			return s, nil
+		},
+		description: fmt.Sprintf("set retry policy %d attempts %s timeout",
+			policy.MaxAttempts, policy.Timeout),
+	}
+}
+
+// WithFixtures sets the directory the replay provider reads its recorded
+// responses from.
+func WithFixtures(dir string) Option {
+	return &option{
+		apply: func(s Settings) (Settings, error) {
+			s.fixtures = dir
+			return s, nil
+		},
+		description: fmt.Sprintf("set fixtures %q", dir),
+	}
+}
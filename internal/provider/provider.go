@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/4sp1/yac/internal/snake"
+
+	"go.uber.org/zap"
+)
+
+// Provider turns a prompt built by [agent.Agent] into a commit message.
+type Provider interface {
+	Complete(ctx context.Context, req Request) (Response, error)
+	fmt.Stringer
+}
+
+type Request struct {
+	// System holds the instructions sent apart from the conversation, it may
+	// be empty.
+	System    string
+	Messages  []Message
+	MaxTokens int
+	// Temperature samples more varied answers as it grows, 0 for the
+	// provider default.
+	Temperature float64
+	// Stream, when set, receives the text while it is generated. Providers
+	// without streaming support write the whole text once complete.
+	Stream io.Writer
+}
+
+// NewRequest wraps a single user prompt in a [Request].
+func NewRequest(user string) Request {
+	return Request{
+		Messages: []Message{
+			{Role: RoleUser, Text: user},
+		},
+	}
+}
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+type Response struct {
+	Text string `json:"text"`
+	// Provider describes which provider produced the response.
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	StopReason string `json:"stop_reason"`
+	Usage      Usage  `json:"usage"`
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+//go:generate stringer -type=Kind
+type Kind int
+
+const (
+	Vertex Kind = iota
+	Anthropic
+	Ollama
+	Openai
+	Replay
+	UpperBound // only use in for loop
+)
+
+func (k Kind) Flag() string {
+	return snake.Case(k.String())
+}
+
+// ParseKind returns the provider kind matching the given flag value.
+func ParseKind(s string) (Kind, error) {
+	for k := range UpperBound {
+		if k.Flag() == s {
+			return k, nil
+		}
+	}
+	return UpperBound, fmt.Errorf("%w %q", ErrUnknownProvider, s)
+}
+
+// New builds the provider of the given kind.
+func New(k Kind, opts ...Option) (Provider, error) {
+	s := Settings{
+		maxTokens: DefaultMaxTokens,
+		retry:     DefaultRetryPolicy,
+		logger:    zap.NewNop(),
+	}
+	var err error
+	for _, opt := range opts {
+		s, err = opt.Apply(s)
+		if err != nil {
+			return nil, fmt.Errorf("option %q: %w", opt, err)
+		}
+	}
+	var p Provider
+	switch k {
+	case Vertex:
+		p, err = newVertex(s)
+	case Anthropic:
+		p, err = newAnthropic(s)
+	case Ollama:
+		p, err = newOllama(s)
+	case Openai:
+		p, err = newOpenai(s)
+	case Replay:
+		p, err = newReplay(s)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownProvider, k)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &retrying{
+		Provider: p,
+		policy:   s.retry,
+		sleep:    sleep,
+		logger:   s.logger,
+	}, nil
+}
+
+const DefaultMaxTokens = 3072
+
+// flush writes the complete text for providers that can't stream it.
+func flush(r Request, res Response) error {
+	if r.Stream == nil || res.Text == "" {
+		return nil
+	}
+	if _, err := io.WriteString(r.Stream, res.Text); err != nil {
+		return fmt.Errorf("write stream: %w", err)
+	}
+	return nil
+}
+
+var ErrUnknownProvider = errors.New("unknown provider")
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fixture is a recorded exchange, the prompt is kept for readability only.
+type fixture struct {
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature,omitempty"`
+	Response    Response  `json:"response"`
+}
+
+// FixtureKey hashes the request system prompt, messages and temperature.
+// Recorded responses are stored under that key so that the same prompt replays
+// the same answer.
+func FixtureKey(r Request) string {
+	h := sha256.New()
+	if r.System != "" {
+		fmt.Fprintf(h, "%s\x00%s\x00", RoleSystem, r.System)
+	}
+	for _, m := range r.Messages {
+		fmt.Fprintf(h, "%s\x00%s\x00", m.Role, m.Text)
+	}
+	if r.Temperature != 0 {
+		fmt.Fprintf(h, "temperature\x00%g\x00", r.Temperature)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func fixturePath(dir string, r Request) string {
+	return filepath.Join(dir, FixtureKey(r)+".json")
+}
+
+// replay answers with the responses recorded in the fixtures directory,
+// without any network call.
+type replay struct {
+	dir string
+}
+
+func newReplay(s Settings) (Provider, error) {
+	if s.fixtures == "" {
+		return nil, ErrMissingFixtures
+	}
+	return &replay{dir: s.fixtures}, nil
+}
+
+func (rp *replay) String() string {
+	return fmt.Sprintf("replay %s", rp.dir)
+}
+
+func (rp *replay) Complete(ctx context.Context, r Request) (Response, error) {
+	path := fixturePath(rp.dir, r)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Response{}, fmt.Errorf("%w %s", ErrNoFixture, path)
+		}
+		return Response{}, fmt.Errorf("read fixture: %w", err)
+	}
+	var f fixture
+	if err := json.Unmarshal(b, &f); err != nil {
+		return Response{}, fmt.Errorf("decode fixture %q: %w", path, err)
+	}
+	if err := flush(r, f.Response); err != nil {
+		return f.Response, err
+	}
+	return f.Response, nil
+}
+
+// recorder saves every successful response of the wrapped provider as a
+// fixture for the replay provider.
+type recorder struct {
+	Provider
+	dir string
+}
+
+// NewRecorder wraps p so its responses are recorded in the dir fixtures
+// directory.
+func NewRecorder(p Provider, dir string) Provider {
+	return &recorder{Provider: p, dir: dir}
+}
+
+func (rc *recorder) Complete(ctx context.Context, r Request) (Response, error) {
+	res, err := rc.Provider.Complete(ctx, r)
+	if err != nil {
+		return res, err
+	}
+	if err := os.MkdirAll(rc.dir, 0700); err != nil {
+		return res, fmt.Errorf("mkdir fixtures: %w", err)
+	}
+	b, err := json.MarshalIndent(fixture{
+		System:      r.System,
+		Messages:    r.Messages,
+		Temperature: r.Temperature,
+		Response:    res,
+	}, "", "  ")
+	if err != nil {
+		return res, fmt.Errorf("encode fixture: %w", err)
+	}
+	if err := os.WriteFile(fixturePath(rc.dir, r), b, 0600); err != nil {
+		return res, fmt.Errorf("write fixture: %w", err)
+	}
+	return res, nil
+}
+
+const DefaultFixtures = ".yac/fixtures"
+
+var (
+	ErrMissingFixtures = errors.New("missing fixtures directory")
+	ErrNoFixture       = errors.New("no recorded fixture")
+)
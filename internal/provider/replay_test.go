@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	req := NewRequest("some prompt")
+
+	recorded, err := NewRecorder(&flaky{}, dir).Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("record: %s", err)
+	}
+
+	p, err := New(Replay, WithFixtures(dir))
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+	res, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("replay: %s", err)
+	}
+	if res.Text != recorded.Text {
+		t.Fatalf("expected %q got %q", recorded.Text, res.Text)
+	}
+
+	_, err = p.Complete(context.Background(), NewRequest("other prompt"))
+	if !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("expected %q got %v", ErrNoFixture, err)
+	}
+	req.Temperature = 1
+	if _, err = p.Complete(context.Background(), req); !errors.Is(err, ErrNoFixture) {
+		t.Fatalf("expected %q for another temperature got %v", ErrNoFixture, err)
+	}
+}
+
+func TestReplayMissingFixtures(t *testing.T) {
+	if _, err := New(Replay); !errors.Is(err, ErrMissingFixtures) {
+		t.Fatalf("expected %q got %v", ErrMissingFixtures, err)
+	}
+}
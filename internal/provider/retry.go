@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RetryPolicy configures how a provider call is retried on transient errors:
+// overloaded or rate limited endpoints, 5xx statuses and network errors.
+type RetryPolicy struct {
+	// MaxAttempts counts the first call, 1 disables retries.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Timeout bounds each attempt, 0 means no timeout.
+	Timeout time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	Timeout:     2 * time.Minute,
+}
+
+type retrying struct {
+	Provider
+	policy RetryPolicy
+	sleep  func(context.Context, time.Duration) error
+
+	logger *zap.Logger
+}
+
+func (r *retrying) Complete(ctx context.Context, req Request) (res Response, err error) {
+	var streamed *countingWriter
+	if req.Stream != nil {
+		streamed = &countingWriter{w: req.Stream}
+		req.Stream = streamed
+	}
+	for attempt := 1; ; attempt++ {
+		res, err = r.attempt(ctx, req)
+		res.Provider = r.Provider.String()
+		if err == nil || attempt >= r.policy.MaxAttempts || !retryable(err) {
+			return res, err
+		}
+		// the partial answer is already on the terminal
+		if streamed != nil && streamed.n > 0 {
+			return res, err
+		}
+		delay := r.delay(attempt, err)
+		// the endpoint asks to wait longer than we are willing to
+		if delay > r.policy.MaxDelay {
+			return res, err
+		}
+		r.logger.Warn("provider call failed, retrying",
+			zap.Stringer("provider", r.Provider),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+		if serr := r.sleep(ctx, delay); serr != nil {
+			return res, fmt.Errorf("%w (last error: %w)", serr, err)
+		}
+	}
+}
+
+func (r *retrying) attempt(ctx context.Context, req Request) (Response, error) {
+	if r.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.policy.Timeout)
+		defer cancel()
+	}
+	return r.Provider.Complete(ctx, req)
+}
+
+// delay is an exponential backoff with full jitter, unless the endpoint told
+// us how long to wait, which may exceed MaxDelay.
+func (r *retrying) delay(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	d := r.policy.BaseDelay << (attempt - 1)
+	if d <= 0 || d > r.policy.MaxDelay {
+		d = r.policy.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
+
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return errors.Is(apiErr, ErrOverloaded) ||
+			errors.Is(apiErr, ErrQuota) ||
+			apiErr.Status >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// parseRetryAfter reads the retry-after header, either in seconds or as an
+// http date.
+func parseRetryAfter(h http.Header, now time.Time) time.Duration {
+	v := h.Get("retry-after")
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
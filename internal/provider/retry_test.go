@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type flaky struct {
+	errs  []error
+	calls int
+	text  string
+}
+
+func (f *flaky) String() string { return "flaky" }
+
+func (f *flaky) Complete(ctx context.Context, r Request) (Response, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if r.Stream != nil && f.text != "" {
+			_, _ = io.WriteString(r.Stream, f.text)
+		}
+		return Response{}, err
+	}
+	return Response{Text: "feat: ok"}, nil
+}
+
+func newRetrying(p Provider, delays *[]time.Duration) *retrying {
+	return &retrying{
+		Provider: p,
+		policy:   DefaultRetryPolicy,
+		sleep: func(_ context.Context, d time.Duration) error {
+			*delays = append(*delays, d)
+			return nil
+		},
+		logger: zap.NewNop(),
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var delays []time.Duration
+	f := &flaky{errs: []error{
+		&APIError{Status: 529},
+		&APIError{Status: http.StatusTooManyRequests, RetryAfter: 7 * time.Second},
+	}}
+	res, err := newRetrying(f, &delays).Complete(context.Background(), NewRequest("prompt"))
+	if err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if res.Text != "feat: ok" || f.calls != 3 {
+		t.Fatalf("unexpected %q after %d calls", res.Text, f.calls)
+	}
+	if len(delays) != 2 {
+		t.Fatalf("expected 2 delays got %v", delays)
+	}
+	if delays[0] <= 0 || delays[0] > DefaultRetryPolicy.BaseDelay {
+		t.Errorf("unexpected backoff %s", delays[0])
+	}
+	if delays[1] != 7*time.Second {
+		t.Errorf("expected retry-after delay got %s", delays[1])
+	}
+}
+
+func TestRetryGiveUp(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		errs          []error
+		stream        bool
+		expectedCalls int
+	}{
+		{
+			name:          "not retryable",
+			errs:          []error{&APIError{Status: http.StatusBadRequest}},
+			expectedCalls: 1,
+		},
+		{
+			name:          "canceled",
+			errs:          []error{context.Canceled},
+			expectedCalls: 1,
+		},
+		{
+			name:          "partially streamed",
+			errs:          []error{&APIError{Status: 529}},
+			stream:        true,
+			expectedCalls: 1,
+		},
+		{
+			name: "retry after too long",
+			errs: []error{
+				&APIError{Status: http.StatusTooManyRequests, RetryAfter: time.Hour},
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "max attempts",
+			errs: []error{
+				&APIError{Status: 500}, &APIError{Status: 500},
+				&APIError{Status: 500}, &APIError{Status: 500},
+			},
+			expectedCalls: DefaultRetryPolicy.MaxAttempts,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var delays []time.Duration
+			f := &flaky{errs: test.errs}
+			req := NewRequest("prompt")
+			if test.stream {
+				f.text = "feat"
+				req.Stream = io.Discard
+			}
+			if _, err := newRetrying(f, &delays).Complete(context.Background(), req); err == nil {
+				t.Fatal("expected error")
+			}
+			if f.calls != test.expectedCalls {
+				t.Fatalf("expected %d calls got %d", test.expectedCalls, f.calls)
+			}
+		})
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("retry-after", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	p, err := New(Anthropic, WithAPIKey("secret"), WithBaseURL(srv.URL),
+		WithRetry(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+	_, err = p.Complete(context.Background(), NewRequest("prompt"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected api error got %v", err)
+	}
+	if apiErr.RetryAfter != 3*time.Second {
+		t.Fatalf("expected 3s retry after got %s", apiErr.RetryAfter)
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// decodeClaudeStream reads the Messages API server-sent events, writing text
+// deltas to w as they arrive while assembling the final response.
+func decodeClaudeStream(r io.Reader, w io.Writer) (claudeResponse, error) {
+	var (
+		res   claudeResponse
+		text  strings.Builder
+		event string
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event:"); ok {
+			event = strings.TrimSpace(name)
+			continue
+		}
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		var e struct {
+			Type    string `json:"type"`
+			Message struct {
+				Model string `json:"model"`
+				Usage struct {
+					InputTokens int `json:"input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type       string `json:"type"`
+				Text       string `json:"text"`
+				StopReason string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &e); err != nil {
+			return res, fmt.Errorf("decode %q event: %w", event, err)
+		}
+		switch e.Type {
+		case "message_start":
+			res.Model = e.Message.Model
+			res.Usage.InputTokens = e.Message.Usage.InputTokens
+		case "content_block_delta":
+			if e.Delta.Type != "text_delta" {
+				continue
+			}
+			text.WriteString(e.Delta.Text)
+			if _, err := io.WriteString(w, e.Delta.Text); err != nil {
+				return res, fmt.Errorf("write delta: %w", err)
+			}
+		case "message_delta":
+			res.StopReason = e.Delta.StopReason
+			res.Usage.OutputTokens = e.Usage.OutputTokens
+		case "error":
+			return res, &APIError{
+				Status:  streamErrorStatus(e.Error.Type),
+				Type:    e.Error.Type,
+				Message: e.Error.Message,
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return res, fmt.Errorf("read stream: %w", err)
+	}
+	res.Content = []claudeContentBlock{{Type: "text", Text: text.String()}}
+	return res, nil
+}
+
+// streamErrorStatus maps the error type of an in-stream error event to the
+// http status it would have had outside of a stream.
+func streamErrorStatus(t string) int {
+	switch t {
+	case "overloaded_error":
+		return 529
+	case "rate_limit_error":
+		return 429
+	case "authentication_error":
+		return 401
+	case "permission_error":
+		return 403
+	case "invalid_request_error":
+		return 400
+	}
+	return 500
+}
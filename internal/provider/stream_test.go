@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const claudeStream = `event: message_start
+data: {"type":"message_start","message":{"model":"claude","usage":{"input_tokens":12}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"feat(cli): "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"stream answers"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}
+
+event: message_stop
+data: {"type":"message_stop"}
+`
+
+func TestAnthropicStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Stream bool `json:"stream"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %s", err)
+		}
+		if !payload.Stream {
+			t.Error("expected stream payload")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(claudeStream))
+	}))
+	defer srv.Close()
+
+	p, err := New(Anthropic, WithAPIKey("secret"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+	var live bytes.Buffer
+	req := NewRequest("some prompt")
+	req.Stream = &live
+	res, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("complete: %s", err)
+	}
+	if res.Text != "feat(cli): stream answers" {
+		t.Fatalf("unexpected text %q", res.Text)
+	}
+	if live.String() != res.Text {
+		t.Fatalf("unexpected streamed text %q", live.String())
+	}
+	if res.Usage.InputTokens != 12 || res.Usage.OutputTokens != 5 {
+		t.Fatalf("unexpected usage %+v", res.Usage)
+	}
+}
+
+func TestClaudeStreamError(t *testing.T) {
+	var live bytes.Buffer
+	_, err := decodeClaudeStream(bytes.NewBufferString(`event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+`), &live)
+	if !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("expected %q got %v", ErrOverloaded, err)
+	}
+}
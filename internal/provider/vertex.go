@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultVertexModel    = "claude-sonnet-4-5@20250929"
+	DefaultVertexLocation = "global"
+	DefaultVertexBaseURL  = "https://aiplatform.googleapis.com"
+)
+
+type vertex struct {
+	projectId string
+	model     string
+	location  string
+	baseURL   string
+	maxTokens int
+	auth      *googleToken
+
+	logger *zap.Logger
+}
+
+func newVertex(s Settings) (Provider, error) {
+	v := &vertex{
+		projectId: s.project,
+		model:     DefaultVertexModel,
+		location:  DefaultVertexLocation,
+		baseURL:   DefaultVertexBaseURL,
+		maxTokens: s.maxTokens,
+		logger:    s.logger.Named("vertex"),
+	}
+	creds, err := findGoogleCredentials(s.credentials)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		v.logger.Debug("no application default credentials, fall back to gcloud")
+	} else if v.projectId == "" {
+		v.projectId = creds.ProjectID
+	}
+	v.auth = &googleToken{
+		creds:  creds,
+		now:    time.Now,
+		logger: v.logger,
+	}
+	if s.model != "" {
+		v.model = s.model
+	}
+	if s.location != "" {
+		v.location = s.location
+	}
+	if s.baseURL != "" {
+		v.baseURL = strings.TrimSuffix(s.baseURL, "/")
+	}
+	return v, nil
+}
+
+func (vc *vertex) String() string {
+	return fmt.Sprintf("vertex %s", vc.model)
+}
+
+func (vc *vertex) Complete(ctx context.Context, r Request) (res Response, err error) {
+	var url = fmt.Sprintf(
+		"%[4]s/v1/projects/%[3]s/locations/%[2]s/publishers/anthropic/models/%[1]s:streamRawPredict",
+		vc.model, vc.location, vc.projectId, vc.baseURL)
+
+	defer func() {
+		if err != nil {
+			vc.logger.Error("vertex complete", zap.Error(err))
+		}
+	}()
+
+	vc.logger.Debug("vertex ai post", zap.String("url", url))
+
+	if vc.projectId == "" {
+		return res, ErrMissingProject
+	}
+
+	maxTokens := vc.maxTokens
+	if r.MaxTokens > 0 {
+		maxTokens = r.MaxTokens
+	}
+
+	payload := struct {
+		Version     string      `json:"anthropic_version"`
+		Messges     []claudeMsg `json:"messages"`
+		System      string      `json:"system"`
+		Stream      bool        `json:"stream"`
+		MaxTokens   int         `json:"max_tokens"`
+		Temperature float64     `json:"temperature,omitempty"`
+	}{
+		Version:     "vertex-2023-10-16",
+		Messges:     newClaudeMsgs(r.Messages),
+		System:      r.System,
+		MaxTokens:   maxTokens,
+		Temperature: r.Temperature,
+		Stream:      r.Stream != nil,
+	}
+
+	var buf bytes.Buffer
+	if err = json.NewEncoder(&buf).Encode(payload); err != nil {
+		return res, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &buf)
+	if err != nil {
+		return res, err
+	}
+
+	token, err := vc.auth.token(ctx)
+	if err != nil {
+		return res, fmt.Errorf("google token: %w", err)
+	}
+
+	vc.logger.Debug("new request for vertexai api",
+		zap.String("anthropic_version", payload.Version),
+		zap.Int("max_tokens", payload.MaxTokens),
+		zap.String("model", vc.model),
+		zap.String("location", vc.location),
+		zap.String("project_id", vc.projectId))
+
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+	hres, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if cerr := hres.Body.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	vc.logger.Debug("Vertex AI API response returned",
+		zap.String("status", hres.Status))
+
+	if err = checkStatus(hres); err != nil {
+		return res, err
+	}
+
+	body, err := decodeClaude(hres.Body, r.Stream)
+	if err != nil {
+		return res, err
+	}
+	if body.Model == "" {
+		body.Model = vc.model
+	}
+	return body.response()
+}
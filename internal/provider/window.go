@@ -0,0 +1,61 @@
+package provider
+
+import "strings"
+
+// DefaultContextWindow is assumed for the models missing from contextWindows.
+const DefaultContextWindow = 8192
+
+// contextWindows lists known model families by name prefix, the first match
+// wins so longer prefixes come first.
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"claude-", 200_000},
+	{"gpt-4.1", 1_047_576},
+	{"gpt-4o", 128_000},
+	{"gpt-4-turbo", 128_000},
+	{"gpt-4", 8192},
+	{"gpt-3.5", 16_385},
+	{"o1", 200_000},
+	{"o3", 200_000},
+	{"o4", 200_000},
+	{"llama3.1", 128_000},
+	{"llama3.2", 128_000},
+	{"llama3.3", 128_000},
+	{"llama3", 8192},
+	{"qwen2.5", 32_768},
+	{"qwen3", 40_960},
+	{"mistral", 32_768},
+	{"gemma3", 128_000},
+	{"gemma2", 8192},
+	{"deepseek-r1", 128_000},
+	{"phi4", 16_384},
+}
+
+// ContextWindow returns the context window, in tokens, of the k provider
+// model, or of its default model when model is empty. The replay provider has
+// no window and returns 0.
+func ContextWindow(k Kind, model string) int {
+	if k == Replay {
+		return 0
+	}
+	if model == "" {
+		switch k {
+		case Vertex:
+			model = DefaultVertexModel
+		case Anthropic:
+			model = DefaultAnthropicModel
+		case Ollama:
+			model = DefaultOllamaModel
+		case Openai:
+			model = DefaultOpenaiModel
+		}
+	}
+	for _, w := range contextWindows {
+		if strings.HasPrefix(model, w.prefix) {
+			return w.tokens
+		}
+	}
+	return DefaultContextWindow
+}
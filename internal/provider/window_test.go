@@ -0,0 +1,23 @@
+package provider
+
+import "testing"
+
+func TestContextWindow(t *testing.T) {
+	for _, test := range []struct {
+		kind     Kind
+		model    string
+		expected int
+	}{
+		{kind: Vertex, expected: 200_000},
+		{kind: Anthropic, model: "claude-opus-4-1-20250805", expected: 200_000},
+		{kind: Openai, expected: 128_000},
+		{kind: Openai, model: "gpt-4", expected: 8192},
+		{kind: Ollama, model: "llama3.2:3b", expected: 128_000},
+		{kind: Ollama, model: "some-local-model", expected: DefaultContextWindow},
+		{kind: Replay, expected: 0},
+	} {
+		if got := ContextWindow(test.kind, test.model); got != test.expected {
+			t.Errorf("%s %q: expected %d got %d", test.kind, test.model, test.expected, got)
+		}
+	}
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
 
 	"github.com/4sp1/yac/cmd"
 	"github.com/spf13/cobra"
 )
 
 func main() {
-	cobra.CheckErr(cmd.NewCLI().ExecuteContext(context.Background()))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cobra.CheckErr(cmd.NewCLI().ExecuteContext(ctx))
 }